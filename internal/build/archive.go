@@ -0,0 +1,114 @@
+package build
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// writeArchive packs the binary as <dir>/<name> and every include, relative
+// to root, under <dir>/ into a gzipped tarball.
+func writeArchive(tarball, dir, root, bin, name string, include []string) error {
+	f, err := os.Create(tarball)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	if strings.HasSuffix(bin, ".exe") {
+		name += ".exe"
+	}
+	if err := addFile(tw, bin, path.Join(dir, name)); err != nil {
+		return err
+	}
+	for _, inc := range include {
+		err := filepath.WalkDir(filepath.Join(root, inc), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			r, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			name := path.Join(dir, filepath.ToSlash(r))
+			if d.IsDir() {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				hdr, err := tar.FileInfoHeader(info, "")
+				if err != nil {
+					return err
+				}
+				hdr.Name = name + "/"
+				return tw.WriteHeader(hdr)
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			return addFile(tw, p, name)
+		})
+		if err != nil {
+			return fmt.Errorf("packing %s: %w", inc, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func addFile(tw *tar.Writer, src, name string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// writeChecksums writes the SHA-256 of every file in the format of
+// sha256sum, so `sha256sum -c checksums.txt` verifies a download.
+func writeChecksums(out string, files []string) error {
+	var b strings.Builder
+	for _, p := range files {
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s  %s\n", hex.EncodeToString(h.Sum(nil)), filepath.Base(p))
+	}
+	return os.WriteFile(out, []byte(b.String()), 0644)
+}
@@ -0,0 +1,211 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// target is a GOOS/GOARCH pair to build for.
+type target struct {
+	os, arch string
+}
+
+func (t target) String() string { return t.os + "/" + t.arch }
+
+func parseTargets(values []string) ([]target, error) {
+	var targets []target
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		goos, goarch, ok := strings.Cut(v, "/")
+		if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+			return nil, fmt.Errorf("invalid target %q (use GOOS/GOARCH, e.g. linux/amd64)", v)
+		}
+		targets = append(targets, target{goos, goarch})
+	}
+	if len(targets) == 0 {
+		targets = append(targets, target{runtime.GOOS, runtime.GOARCH})
+	}
+	return targets, nil
+}
+
+// BuildCmd returns the `build` command.
+func BuildCmd() *cobra.Command {
+	var targets []string
+	var version, output, pkg, ldflags string
+	var archive bool
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Compile the project for release with version information",
+		Long: "Compile the project with its version, commit and build time set through -ldflags,\n" +
+			"for the host or every --target. Binaries are written to dist/ as\n" +
+			"<name>-<version>-<os>-<arch>, each packed with the example config and resources/\n" +
+			"into a .tar.gz, and listed with their SHA-256 in checksums.txt. Defaults come from\n" +
+			"the build section of kygo.json.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := project.Root()
+			proj, err := project.Current()
+			if err != nil {
+				return err
+			}
+			b := proj.Build
+			f := cmd.Flags()
+			if f.Changed("target") {
+				b.Targets = targets
+			}
+			if f.Changed("output") {
+				b.Output = output
+			}
+			if f.Changed("package") {
+				b.Package = pkg
+			}
+			if f.Changed("ldflags") {
+				b.Ldflags = ldflags
+			}
+			if f.Changed("archive") {
+				b.Archive = archive
+			}
+			if b.Name == "" {
+				b.Name = filepath.Base(root)
+			}
+			list, err := parseTargets(b.Targets)
+			if err != nil {
+				return err
+			}
+			if _, err := exec.LookPath("go"); err != nil {
+				return fmt.Errorf("go not found in PATH: %w", err)
+			}
+			if version == "" {
+				version = gitOutput(root, "describe", "--tags", "--always", "--dirty")
+			}
+			if version == "" {
+				version = "dev"
+			}
+			commit := gitOutput(root, "rev-parse", "--short", "HEAD")
+			if commit == "" {
+				commit = "unknown"
+			}
+			buildTime := time.Now().UTC().Format(time.RFC3339)
+			flags := fmt.Sprintf("-X %[1]s.version=%[2]s -X %[1]s.commit=%[3]s -X %[1]s.buildTime=%[4]s",
+				b.VersionPackage, version, commit, buildTime)
+			if b.Ldflags != "" {
+				flags += " " + b.Ldflags
+			}
+
+			out := project.Path(b.Output)
+			if err := os.MkdirAll(out, 0755); err != nil {
+				return err
+			}
+			include := includes(root, b.Include)
+			ui.Info(fmt.Sprintf("Building %s %s (%s)", b.Name, version, commit))
+			var artefacts []string
+			for _, t := range list {
+				base := fmt.Sprintf("%s-%s-%s-%s", b.Name, strings.ReplaceAll(version, "/", "-"), t.os, t.arch)
+				bin := filepath.Join(out, base)
+				if t.os == "windows" {
+					bin += ".exe"
+				}
+				if err := compile(root, b.Package, bin, flags, t); err != nil {
+					return err
+				}
+				artefacts = append(artefacts, bin)
+				ui.Println(fmt.Sprintf("  %-14s %s", t, rel(root, bin)))
+				if !b.Archive {
+					continue
+				}
+				tarball := filepath.Join(out, base+".tar.gz")
+				if err := writeArchive(tarball, base, root, bin, b.Name, include); err != nil {
+					return err
+				}
+				artefacts = append(artefacts, tarball)
+				ui.Println(fmt.Sprintf("  %-14s %s", "", rel(root, tarball)))
+			}
+			sums := filepath.Join(out, "checksums.txt")
+			if err := writeChecksums(sums, artefacts); err != nil {
+				return err
+			}
+			ui.Successf("Built %d target(s) into %s", len(list), rel(root, out))
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringSliceVar(&targets, "target", nil, "GOOS/GOARCH pairs to build for, comma-separated (default: build.targets from kygo.json, or the host)")
+	f.StringVar(&version, "version", "", "version to stamp (default: git describe --tags --always --dirty)")
+	f.StringVarP(&output, "output", "o", "", "directory for artefacts (default: build.output from kygo.json, dist)")
+	f.StringVar(&pkg, "package", "", "main package to build (default: build.package from kygo.json, .)")
+	f.StringVar(&ldflags, "ldflags", "", "extra linker flags, e.g. \"-s -w\" (default: build.ldflags from kygo.json)")
+	f.BoolVar(&archive, "archive", true, "pack each binary with the example config and resources/ into a .tar.gz (overrides build.archive from kygo.json)")
+	return cmd
+}
+
+// compile runs go build for one target. CGO is disabled unless set in the
+// environment, so cross-compiling doesn't need a C toolchain.
+func compile(root, pkg, bin, ldflags string, t target) error {
+	c := exec.Command("go", "build", "-trimpath", "-ldflags", ldflags, "-o", bin, pkg)
+	c.Dir = root
+	c.Env = append(os.Environ(), "GOOS="+t.os, "GOARCH="+t.arch)
+	if _, ok := os.LookupEnv("CGO_ENABLED"); !ok {
+		c.Env = append(c.Env, "CGO_ENABLED=0")
+	}
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	if err := c.Run(); err != nil {
+		ui.Errorf("%s", strings.TrimRight(out.String(), "\n"))
+		return fmt.Errorf("building for %s: %w", t, err)
+	}
+	return nil
+}
+
+// includes lists the files and directories packed next to the binary: the
+// example config, resources/ and build.include, those that exist.
+func includes(root string, extra []string) []string {
+	candidates := append([]string{filepath.Base(config.DiscoverExample(root)), "resources"}, extra...)
+	var found []string
+	seen := map[string]bool{}
+	for _, p := range candidates {
+		p = filepath.Clean(filepath.FromSlash(p))
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		if _, err := os.Stat(filepath.Join(root, p)); err == nil {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// gitOutput runs git in dir and returns its trimmed output, or "" when git
+// is missing or fails (e.g. outside a repository).
+func gitOutput(dir string, args ...string) string {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	out, err := c.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func rel(root, p string) string {
+	if r, err := filepath.Rel(root, p); err == nil && !strings.HasPrefix(r, "..") {
+		return filepath.ToSlash(r)
+	}
+	return p
+}
@@ -0,0 +1,375 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// ConfigCmd returns the `config` command group.
+func ConfigCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and edit the project configuration",
+	}
+	root.PersistentFlags().String("file", "", "config file (default: config.json, config.yaml, config.yml or config.toml)")
+	root.AddCommand(makeShowCmd(), makeGetCmd(), makeSetCmd(), makeValidateCmd(), makeSchemaCmd(), makeSyncCmd(), makeConvertCmd(), makeGenerateCmd())
+	return root
+}
+
+func makeShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the resolved configuration",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			file, _ := c.Flags().GetString("file")
+			withSources, _ := c.Flags().GetBool("sources")
+			cfg, err := Load(file)
+			if err != nil {
+				return err
+			}
+			entries := cfg.Entries()
+			keyWidth, valueWidth := 0, 0
+			for i, e := range entries {
+				entries[i].Value = displayValue(e)
+				keyWidth = max(keyWidth, len(e.Key))
+				valueWidth = max(valueWidth, len(entries[i].Value))
+			}
+			for _, e := range entries {
+				if withSources {
+					ui.Println(fmt.Sprintf("%-*s = %-*s  [%s]", keyWidth, e.Key, valueWidth, e.Value, sourceLabel(e)))
+				} else {
+					ui.Println(fmt.Sprintf("%-*s = %s", keyWidth, e.Key, e.Value))
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("sources", false, "show where each value was read from")
+	return cmd
+}
+
+// sourceLabel names the environment variable for env and .env sources so
+// it's obvious which variable to change.
+func sourceLabel(e Entry) string {
+	switch e.Source {
+	case SourceEnv, SourceDotEnv:
+		return e.Source + " " + EnvName(e.Key)
+	default:
+		return e.Source
+	}
+}
+
+func makeGetCmd() *cobra.Command {
+	var reveal bool
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the resolved value of a key (or every key below it)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			file, _ := c.Flags().GetString("file")
+			cfg, err := Load(file)
+			if err != nil {
+				return err
+			}
+			key := args[0]
+			var below []Entry
+			for _, e := range cfg.Entries() {
+				if e.Key == key {
+					if !reveal {
+						ui.Println(displayValue(e))
+						return nil
+					}
+					if err := cfg.Resolved(key); err != nil {
+						return err
+					}
+					ui.Println(e.Value)
+					return nil
+				}
+				if strings.HasPrefix(e.Key, key+".") {
+					below = append(below, e)
+				}
+			}
+			if len(below) == 0 {
+				return fmt.Errorf("unknown config key: %s", key)
+			}
+			for _, e := range below {
+				value := displayValue(e)
+				if reveal {
+					if err := cfg.Resolved(e.Key); err != nil {
+						return err
+					}
+					value = e.Value
+				}
+				ui.Println(fmt.Sprintf("%s = %s", e.Key, value))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&reveal, "reveal", false, "print the secret itself instead of its reference or asterisks")
+	return cmd
+}
+
+func makeSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a key in the config file, preserving key order and formatting",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(c *cobra.Command, args []string) error {
+			file, _ := c.Flags().GetString("file")
+			if file == "" {
+				file = Discover("")
+			}
+			key, value := args[0], args[1]
+			encoded, err := encodeValue(key, value)
+			if err != nil {
+				return err
+			}
+			if err := SetFile(file, key, encoded); err != nil {
+				return err
+			}
+			shown := string(encoded)
+			if isSecret(key) && !IsReference(value) {
+				shown = "****"
+			}
+			ui.Successf("Set %s = %s in %s", key, shown, file)
+			return nil
+		},
+	}
+}
+
+func makeValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config file against the config schema",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			file, _ := c.Flags().GetString("file")
+			issues, err := Validate(file)
+			if err != nil {
+				return err
+			}
+			errs := 0
+			for _, i := range issues {
+				if i.Warning {
+					ui.Warning(i.String())
+					continue
+				}
+				errs++
+				ui.Error(i.String())
+			}
+			if errs > 0 {
+				return fmt.Errorf("config is invalid: %d error(s)", errs)
+			}
+			ui.Success("Config is valid")
+			return nil
+		},
+	}
+}
+
+func makeSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the config JSON Schema",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			check, _ := c.Flags().GetBool("check")
+			if !check {
+				ui.Println(strings.TrimSpace(string(SchemaJSON)))
+				return nil
+			}
+			problems := CheckStruct()
+			for _, p := range problems {
+				ui.Error(p)
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("config schema and Config struct differ: %d problem(s)", len(problems))
+			}
+			ui.Success("Config struct matches the schema")
+			return nil
+		},
+	}
+	cmd.Flags().Bool("check", false, "verify the CLI's Config struct matches the schema")
+	return cmd
+}
+
+func makeSyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Compare the keys of config.example.json and config.json",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			file, _ := c.Flags().GetString("file")
+			if file == "" {
+				file = Discover("")
+			}
+			example, _ := c.Flags().GetString("example")
+			if example == "" {
+				example = DiscoverExample("")
+			}
+			r, err := Sync(example, file)
+			if err != nil {
+				return err
+			}
+			for _, k := range r.MissingFromConfig {
+				ui.Error(fmt.Sprintf("%s is in %s but missing from %s", k, example, file))
+			}
+			for _, k := range r.MissingFromExample {
+				ui.Warning(fmt.Sprintf("%s is in %s but missing from %s", k, file, example))
+			}
+			if len(r.MissingFromConfig) > 0 {
+				return fmt.Errorf("%s is missing %d key(s) from %s", file, len(r.MissingFromConfig), example)
+			}
+			if len(r.MissingFromExample) == 0 {
+				ui.Success(fmt.Sprintf("%s and %s have the same keys", file, example))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("example", "", "example config file to compare against (default: config.example.*)")
+	return cmd
+}
+
+func makeConvertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert <src> [dst]",
+		Short: "Translate a config file between JSON, YAML and TOML",
+		Example: "  kygo config convert config.json config.yaml\n" +
+			"  kygo config convert config.example.json --to toml",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(c *cobra.Command, args []string) error {
+			to, _ := c.Flags().GetString("to")
+			force, _ := c.Flags().GetBool("force")
+			src := args[0]
+			from, err := FormatOf(src)
+			if err != nil {
+				return err
+			}
+			var dst string
+			if len(args) == 2 {
+				dst = args[1]
+			} else {
+				if to == "" {
+					return fmt.Errorf("give a destination file or --to json|yaml|toml")
+				}
+				dst = strings.TrimSuffix(src, filepath.Ext(src)) + Extension(to)
+			}
+			dstFormat, err := FormatOf(dst)
+			if err != nil {
+				return err
+			}
+			if to != "" && to != dstFormat {
+				return fmt.Errorf("--to %s does not match %s", to, dst)
+			}
+			if _, err := os.Stat(dst); err == nil && !force {
+				return fmt.Errorf("file already exists: %s (use --force to overwrite)", dst)
+			}
+			b, err := os.ReadFile(src)
+			if err != nil {
+				return err
+			}
+			out, err := Convert(b, from, dstFormat)
+			if err != nil {
+				return fmt.Errorf("%s: %w", src, err)
+			}
+			if err := os.WriteFile(dst, out, 0644); err != nil {
+				return err
+			}
+			ui.Successf("Converted %s to %s", src, dst)
+			return nil
+		},
+	}
+	cmd.Flags().String("to", "", "target format (json, yaml or toml) when no destination is given")
+	cmd.Flags().Bool("force", false, "overwrite the destination file")
+	return cmd
+}
+
+func makeGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Regenerate Go types for the application's own config sections",
+		Long: "Reads the example config and writes a typed struct plus loader to <out>/<section>.go\n" +
+			"for every top-level section that kyugo itself doesn't model.",
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			example, _ := c.Flags().GetString("example")
+			if example == "" {
+				example = DiscoverExample("")
+			}
+			out, _ := c.Flags().GetString("out")
+			if out == "" {
+				proj, err := project.Current()
+				if err != nil {
+					return err
+				}
+				out = project.Path(proj.Dirs.Config)
+			}
+			sections, err := AppSections(example)
+			if err != nil {
+				return err
+			}
+			if len(sections) == 0 {
+				ui.Info(fmt.Sprintf("No application sections found in %s", example))
+				return nil
+			}
+			for _, section := range sections {
+				code, err := GenerateSection(example, section)
+				if err != nil {
+					return err
+				}
+				target := filepath.Join(out, strings.ReplaceAll(section, "-", "_")+".go")
+				if err := WriteGenerated(target, code); err != nil {
+					return err
+				}
+				ui.Successf("Generated %s", target)
+			}
+			if f, _ := FormatOf(example); f == FormatYAML {
+				added, err := RequireYAML(project.Root())
+				if err != nil {
+					return err
+				}
+				if added {
+					ui.Info(fmt.Sprintf("Added %s to go.mod; run `go mod tidy` to update go.sum", YAMLModule))
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("example", "", "example config to read (default: config.example.*)")
+	cmd.Flags().String("out", "", "directory for the generated Go files (default: dirs.config from kygo.json)")
+	return cmd
+}
+
+// displayValue returns the value to print for e: the secret reference when
+// there is one, asterisks for other secrets, the value otherwise.
+func displayValue(e Entry) string {
+	switch {
+	case e.Ref != "":
+		return e.Ref
+	case isSecret(e.Key) && e.Value != "":
+		return "****"
+	}
+	return e.Value
+}
+
+// isSecret reports whether the value stored under key must not be printed.
+func isSecret(key string) bool {
+	last := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+	switch last {
+	case "password", "passwd", "secret", "token", "api_key", "apikey", "private_key":
+		return true
+	}
+	for _, suffix := range []string{"_password", "_secret", "_token"} {
+		if strings.HasSuffix(last, suffix) {
+			return true
+		}
+	}
+	return false
+}
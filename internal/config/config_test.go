@@ -0,0 +1,175 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files, keyed by slash-separated name, under a
+// temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func entries(c *Config) map[string]Entry {
+	m := map[string]Entry{}
+	for _, e := range c.Entries() {
+		m[e.Key] = e
+	}
+	return m
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	SetEnvironment("")
+	dir := writeFiles(t, map[string]string{
+		"config.json": `{
+			"app": {"name": "file", "environment": "staging"},
+			"database": {"type": "postgres", "host": "file", "port": 5432, "user": "file", "dbname": "file"}
+		}`,
+		"config.staging.json": `{"database": {"host": "overlay", "port": 6432, "dbname": "overlay"}}`,
+		".env":                "KYGO_DATABASE_HOST=dotenv\nKYGO_DATABASE_USER=dotenv\n",
+	})
+	t.Setenv("KYGO_DATABASE_USER", "env")
+
+	cfg, err := Load(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key, value, source string
+	}{
+		{"app.name", "file", "config.json"},
+		{"app.environment", "staging", "config.json"},
+		{"database.dbname", "overlay", "config.staging.json"},
+		{"database.port", "6432", "config.staging.json"},
+		{"database.host", "dotenv", SourceDotEnv},
+		{"database.user", "env", SourceEnv},
+		{"database.sslmode", "", SourceDefault},
+	}
+	got := entries(cfg)
+	for _, tt := range tests {
+		e := got[tt.key]
+		if e.Value != tt.value || e.Source != tt.source {
+			t.Errorf("%s = %q from %s, want %q from %s", tt.key, e.Value, e.Source, tt.value, tt.source)
+		}
+	}
+}
+
+func TestLoadSelectedEnvironment(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config.json":      `{"app": {"name": "file"}}`,
+		"config.prod.json": `{"app": {"name": "prod"}}`,
+	})
+
+	t.Setenv("KYGO_ENV", "prod")
+	cfg, err := Load(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.App.Name != "prod" || cfg.Environment() != "prod" {
+		t.Errorf("KYGO_ENV=prod: app.name = %q, environment = %q", cfg.App.Name, cfg.Environment())
+	}
+
+	// --env wins over KYGO_ENV and must name an existing overlay
+	SetEnvironment("test")
+	defer SetEnvironment("")
+	if _, err := Load(filepath.Join(dir, "config.json")); err == nil || !strings.Contains(err.Error(), "config.test.json does not exist") {
+		t.Errorf("--env test: err = %v, want a missing config.test.json", err)
+	}
+}
+
+func TestLoadReferences(t *testing.T) {
+	SetEnvironment("")
+	dir := writeFiles(t, map[string]string{
+		"config.json": `{
+			"database": {
+				"type": "postgres",
+				"host": "env:TEST_DB_HOST",
+				"user": "file:secrets/user",
+				"password": "env:TEST_DB_PASSWORD",
+				"dbname": "app"
+			},
+			"databases": {
+				"broken": {"type": "postgres", "password": "env:TEST_UNSET_PASSWORD"}
+			}
+		}`,
+		"secrets/user": "reader\n",
+		".env":         "TEST_DB_PASSWORD=from-dotenv\n",
+	})
+	t.Setenv("TEST_DB_HOST", "db.internal")
+
+	cfg, err := Load(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key, value, ref string
+	}{
+		{"database.host", "db.internal", "env:TEST_DB_HOST"},
+		{"database.user", "reader", "file:secrets/user"},
+		{"database.password", "from-dotenv", "env:TEST_DB_PASSWORD"},
+		{"database.dbname", "app", ""},
+		{"databases.broken.password", "", "env:TEST_UNSET_PASSWORD"},
+	}
+	got := entries(cfg)
+	for _, tt := range tests {
+		e := got[tt.key]
+		if e.Value != tt.value || e.Ref != tt.ref {
+			t.Errorf("%s = %q (ref %q), want %q (ref %q)", tt.key, e.Value, e.Ref, tt.value, tt.ref)
+		}
+	}
+
+	if err := cfg.Resolved("database"); err != nil {
+		t.Errorf("Resolved(database) = %v", err)
+	}
+	if _, err := cfg.Connection(DefaultConnection); err != nil {
+		t.Errorf("Connection(default) = %v", err)
+	}
+	if err := cfg.Resolved(""); err == nil || !strings.Contains(err.Error(), "TEST_UNSET_PASSWORD is not set") {
+		t.Errorf("Resolved() = %v, want the unset variable", err)
+	}
+	if _, err := cfg.Connection("broken"); err == nil {
+		t.Error("Connection(broken) succeeded with an unresolved password")
+	}
+
+	// ReadFile keeps the references as written
+	raw, err := ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw.Database.Password != "env:TEST_DB_PASSWORD" {
+		t.Errorf("ReadFile: database.password = %q", raw.Database.Password)
+	}
+}
+
+func TestLoadEnvOnlyConnection(t *testing.T) {
+	SetEnvironment("")
+	dir := writeFiles(t, map[string]string{"config.json": `{"database": {"type": "sqlite", "dbname": ":memory:"}}`})
+	t.Setenv("KYGO_DATABASES_ANALYTICS_TYPE", "postgres")
+	t.Setenv("KYGO_DATABASES_ANALYTICS_HOST", "analytics")
+
+	cfg, err := Load(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := cfg.Connection("analytics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Type != "postgres" || db.Host != "analytics" {
+		t.Errorf("analytics = %+v", db)
+	}
+}
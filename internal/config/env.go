@@ -0,0 +1,320 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix prefixes every environment variable that overrides a config
+// key, e.g. database.host is read from KYGO_DATABASE_HOST.
+const EnvPrefix = "KYGO_"
+
+// Config sources in increasing order of precedence.
+const (
+	SourceDefault = "default"
+	SourceDotEnv  = ".env"
+	SourceEnv     = "env"
+)
+
+// EnvName returns the environment variable that overrides key.
+func EnvName(key string) string {
+	r := strings.NewReplacer(".", "_", "-", "_")
+	return EnvPrefix + strings.ToUpper(r.Replace(key))
+}
+
+// Getenv returns the value of an environment variable, falling back to the
+// .env file in the working directory when it isn't set.
+func Getenv(key string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	dotenv, _ := LoadDotEnv(".env")
+	return dotenv[key]
+}
+
+// LoadDotEnv parses a .env file of KEY=VALUE lines. A missing file yields an
+// empty map and no error.
+func LoadDotEnv(path string) (map[string]string, error) {
+	vars := map[string]string{}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return vars, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			uq, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+			value = uq
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			// unquoted values may carry a trailing comment
+			if i := strings.Index(value, " #"); i != -1 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		vars[key] = value
+	}
+	return vars, sc.Err()
+}
+
+// lookupEnv returns key from the process environment or, failing that,
+// from dotenv.
+func lookupEnv(key string, dotenv map[string]string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return dotenv[key]
+}
+
+// applyEnv overrides every config key that has a matching KYGO_ variable in
+// the environment or, failing that, in dotenv. It records the source of each
+// override and returns how many keys were overridden.
+func (c *Config) applyEnv(dotenv map[string]string) (int, error) {
+	c.envConnections(dotenv)
+	n := 0
+	err := walk(reflect.ValueOf(c).Elem(), "", func(key string, v reflect.Value) error {
+		name := EnvName(key)
+		value, ok := os.LookupEnv(name)
+		source := SourceEnv
+		if !ok {
+			value, ok = dotenv[name]
+			source = SourceDotEnv
+		}
+		if !ok {
+			return nil
+		}
+		if err := setFromString(v, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		c.sources[key] = source
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// envConnections adds the named connections that are only configured by
+// KYGO_DATABASES_<NAME>_<FIELD> variables, e.g. KYGO_DATABASES_ANALYTICS_HOST,
+// so applyEnv fills them in. Their names are the lower-cased <NAME>.
+func (c *Config) envConnections(dotenv map[string]string) {
+	prefix := EnvName("databases") + "_"
+	var fields []string
+	t := reflect.TypeOf(Database{})
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" && t.Field(i).Type.Kind() != reflect.Map {
+			fields = append(fields, "_"+strings.ToUpper(name))
+		}
+	}
+	names := make([]string, 0, len(dotenv))
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		names = append(names, name)
+	}
+	for name := range dotenv {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		for _, f := range fields {
+			conn, ok := strings.CutSuffix(rest, f)
+			if !ok || conn == "" {
+				continue
+			}
+			if !c.hasConnection(prefix + conn) {
+				if c.Databases == nil {
+					c.Databases = map[string]Database{}
+				}
+				c.Databases[strings.ToLower(conn)] = Database{}
+			}
+			break
+		}
+	}
+}
+
+// hasConnection reports whether the default or a named connection has the
+// KYGO_ variable prefix envPrefix.
+func (c *Config) hasConnection(envPrefix string) bool {
+	if envPrefix == EnvName("databases."+DefaultConnection) {
+		return true
+	}
+	for name := range c.Databases {
+		if EnvName("databases."+name) == envPrefix {
+			return true
+		}
+	}
+	return false
+}
+
+// walk calls fn for every leaf config value below v, passing its dotted JSON
+// key. Values handed to fn are settable, including map entries.
+func walk(v reflect.Value, prefix string, fn func(key string, v reflect.Value) error) error {
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name := jsonName(t.Field(i))
+			if name == "" {
+				continue
+			}
+			if err := walk(v.Field(i), join(name), fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			if err := walk(elem, join(k.String()), fn); err != nil {
+				return err
+			}
+			v.SetMapIndex(k, elem)
+		}
+		return nil
+	default:
+		return fn(prefix, v)
+	}
+}
+
+// setFromString parses s according to v's kind and stores it in v. Slices
+// are read as comma-separated lists.
+func setFromString(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := strings.Split(s, ",")
+		sl := reflect.MakeSlice(v.Type(), 0, len(parts))
+		for _, p := range parts {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setFromString(elem, strings.TrimSpace(p)); err != nil {
+				return err
+			}
+			sl = reflect.Append(sl, elem)
+		}
+		v.Set(sl)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// markFileKeys records source for every key present in the decoded file.
+func markFileKeys(raw map[string]any, prefix, source string, sources map[string]string) {
+	for k, v := range raw {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]any); ok {
+			markFileKeys(m, key, source, sources)
+			continue
+		}
+		sources[key] = source
+	}
+}
+
+// Entry is a single resolved config key.
+type Entry struct {
+	Key    string
+	Value  string
+	Source string
+	// Ref is the env: or file: reference Value was resolved from, if any.
+	Ref string
+}
+
+// Entries returns every config key with its effective value and the source
+// it was read from.
+func (c *Config) Entries() []Entry {
+	var entries []Entry
+	_ = walk(reflect.ValueOf(c).Elem(), "", func(key string, v reflect.Value) error {
+		source := c.sources[key]
+		if source == "" {
+			source = SourceDefault
+		}
+		entries = append(entries, Entry{Key: key, Value: formatValue(v), Source: source, Ref: c.refs[key]})
+		return nil
+	})
+	return entries
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Slice {
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = formatValue(v.Index(i))
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Path returns the file the config was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+func dotEnvPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), ".env")
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SourceFile marks an environment taken from app.environment in the base
+// config file; a missing config.<env>.json is not an error in that case.
+const SourceFile = "app.environment"
+
+// environment is the environment selected on the command line (--env).
+var environment string
+
+// SetEnvironment selects the environment whose config.<env>.<ext> overlay
+// Load merges on top of the base config. It takes precedence over KYGO_ENV
+// and app.environment.
+func SetEnvironment(env string) {
+	environment = env
+}
+
+// Environment returns the environment the config was resolved for.
+func (c *Config) Environment() string {
+	return c.App.Environment
+}
+
+// resolveEnvironment picks the environment from --env, KYGO_ENV or the
+// base file's app.environment, in that order, and reports which one won.
+func resolveEnvironment(raw map[string]any, dotenv map[string]string) (string, string) {
+	if environment != "" {
+		return environment, "--env"
+	}
+	if env := lookupEnv("KYGO_ENV", dotenv); env != "" {
+		return env, "KYGO_ENV"
+	}
+	if app, ok := raw["app"].(map[string]any); ok {
+		if env, ok := app["environment"].(string); ok && env != "" {
+			return env, SourceFile
+		}
+	}
+	return "", ""
+}
+
+// overlayPath returns config.<env>.json for a base path of config.json (and
+// likewise for the other formats).
+func overlayPath(base, env string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + env + ext
+}
+
+// deepMerge merges src into dst. Nested objects are merged key by key while
+// any other value (including arrays) in src replaces the one in dst.
+func deepMerge(dst, src map[string]any) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				deepMerge(dm, sm)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
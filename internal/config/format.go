@@ -0,0 +1,535 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/go-kyugo/kygo/internal/project"
+)
+
+// Supported config file formats.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// extensions are the config file extensions Discover looks for, in order.
+var extensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// Discover returns the first of config.json, config.yaml, config.yml and
+// config.toml found in dir, or config.json when there is none. An empty dir
+// means the project root.
+func Discover(dir string) string {
+	return discover(dir, "config")
+}
+
+// DiscoverExample is like Discover for config.example.* files.
+func DiscoverExample(dir string) string {
+	return discover(dir, "config.example")
+}
+
+func discover(dir, base string) string {
+	if dir == "" {
+		dir = project.Root()
+	}
+	for _, ext := range extensions {
+		p := filepath.Join(dir, base+ext)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return filepath.Join(dir, base+extensions[0])
+}
+
+// FormatOf returns the config format implied by the file extension.
+func FormatOf(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	}
+	return "", fmt.Errorf("unsupported config format: %s (use .json, .yaml, .yml or .toml)", filepath.Base(path))
+}
+
+// Extension returns the file extension used for format.
+func Extension(format string) string {
+	if format == FormatYAML {
+		return ".yaml"
+	}
+	return "." + format
+}
+
+// readFile decodes a config file of any supported format into a generic
+// map with JSON semantics (numbers are float64).
+func readFile(path string) (map[string]any, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := decode(format, b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return raw, nil
+}
+
+func decode(format string, b []byte) (map[string]any, error) {
+	var raw map[string]any
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	case FormatYAML:
+		if err := yaml.Unmarshal(b, &raw); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(b, &raw); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+	// round-trip through JSON so every format yields the same value types
+	j, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	raw = nil
+	if err := json.Unmarshal(j, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		raw = map[string]any{}
+	}
+	return raw, nil
+}
+
+// parseNode decodes a config document into an ordered yaml.Node mapping so
+// key order survives conversions and edits.
+func parseNode(format string, b []byte) (*yaml.Node, error) {
+	switch format {
+	case FormatJSON, FormatYAML:
+		// JSON is a subset of YAML
+		var doc yaml.Node
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 {
+			return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+		}
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("config file must contain an object")
+		}
+		if format == FormatJSON {
+			blockStyle(root)
+		}
+		return root, nil
+	case FormatTOML:
+		var raw map[string]any
+		md, err := toml.Decode(string(b), &raw)
+		if err != nil {
+			return nil, err
+		}
+		order := make([][]string, 0, len(md.Keys()))
+		for _, key := range md.Keys() {
+			order = append(order, key)
+		}
+		return tomlNode(raw, nil, order)
+	}
+	return nil, fmt.Errorf("unsupported config format: %s", format)
+}
+
+// tomlNode converts a decoded TOML value to a yaml.Node, ordering the keys
+// of tables as they appear in the document (order, from the decoder's
+// metadata). Arrays of tables list their keys without an index, so every
+// element shares the order of its array.
+func tomlNode(v any, path []string, order [][]string) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range tomlKeys(v, path, order) {
+			child, err := tomlNode(v[k], append(append([]string{}, path...), k), order)
+			if err != nil {
+				return nil, err
+			}
+			setNode(n, k, child)
+		}
+		return n, nil
+	case []map[string]any:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			child, err := tomlNode(item, path, order)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, child)
+		}
+		return n, nil
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			child, err := tomlNode(item, path, order)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, child)
+		}
+		return n, nil
+	}
+	var n yaml.Node
+	if err := n.Encode(v); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// tomlKeys returns the keys of table m at path in document order; keys the
+// metadata doesn't list follow, sorted.
+func tomlKeys(m map[string]any, path []string, order [][]string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, key := range order {
+		if len(key) != len(path)+1 || !hasPath(key, path) {
+			continue
+		}
+		k := key[len(path)]
+		if _, ok := m[k]; ok && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	var rest []string
+	for k := range m {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+func hasPath(key, prefix []string) bool {
+	for i, p := range prefix {
+		if key[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// blockStyle clears the flow/quoting styles JSON input carries so YAML
+// output uses block mappings and plain scalars; short lists stay inline.
+func blockStyle(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		n.Style = 0
+	case yaml.ScalarNode:
+		n.Style = 0
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// mappingAt returns the mapping under path, creating it when missing.
+func mappingAt(root *yaml.Node, path []string) *yaml.Node {
+	n := root
+	for _, seg := range path {
+		child := lookupNode(n, seg)
+		if child == nil || child.Kind != yaml.MappingNode {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			setNode(n, seg, child)
+		}
+		n = child
+	}
+	return n
+}
+
+func lookupNode(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setNode replaces the value of key in mapping, appending it when absent.
+func setNode(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			// keep comments attached to the old value
+			value.HeadComment = mapping.Content[i+1].HeadComment
+			value.LineComment = mapping.Content[i+1].LineComment
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	k := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	mapping.Content = append(mapping.Content, k, value)
+}
+
+// encodeNode renders an ordered mapping in the given format.
+func encodeNode(format string, root *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatJSON:
+		writeJSONNode(&buf, root, "")
+		buf.WriteString("\n")
+	case FormatYAML:
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(root); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		if err := writeTOMLTable(&buf, root, nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// Convert translates a config document between formats, keeping key order.
+func Convert(b []byte, from, to string) ([]byte, error) {
+	root, err := parseNode(from, b)
+	if err != nil {
+		return nil, err
+	}
+	if from == FormatYAML && to == FormatYAML {
+		return b, nil
+	}
+	if from != FormatYAML {
+		blockStyle(root)
+	}
+	return encodeNode(to, root)
+}
+
+func writeJSONNode(buf *bytes.Buffer, n *yaml.Node, indent string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			buf.WriteString(indent + "  " + quote(n.Content[i].Value) + ": ")
+			writeJSONNode(buf, n.Content[i+1], indent+"  ")
+			if i+2 < len(n.Content) {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	case yaml.SequenceNode:
+		buf.WriteString("[")
+		for i, c := range n.Content {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeJSONNode(buf, c, indent)
+		}
+		buf.WriteString("]")
+	case yaml.AliasNode:
+		writeJSONNode(buf, n.Alias, indent)
+	default:
+		buf.WriteString(scalarJSON(n))
+	}
+}
+
+// scalarJSON renders a YAML scalar as a JSON literal.
+func scalarJSON(n *yaml.Node) string {
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return quote(n.Value)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return quote(n.Value)
+	}
+	return string(b)
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(k string) string {
+	if bareKey.MatchString(k) {
+		return k
+	}
+	return quote(k)
+}
+
+// writeTOMLTable writes the keys of mapping n: plain values first, then
+// nested tables as [a.b] sections.
+func writeTOMLTable(buf *bytes.Buffer, n *yaml.Node, path []string) error {
+	var tables []int
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		v := n.Content[i+1]
+		if v.Kind == yaml.MappingNode {
+			tables = append(tables, i)
+			continue
+		}
+		if v.Kind == yaml.ScalarNode && v.Tag == "!!null" {
+			continue // TOML has no null
+		}
+		s, err := tomlValue(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(append(path, n.Content[i].Value), "."), err)
+		}
+		buf.WriteString(tomlKey(n.Content[i].Value) + " = " + s + "\n")
+	}
+	for _, i := range tables {
+		sub := append(append([]string{}, path...), n.Content[i].Value)
+		keys := make([]string, len(sub))
+		for j, k := range sub {
+			keys[j] = tomlKey(k)
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("[" + strings.Join(keys, ".") + "]\n")
+		if err := writeTOMLTable(buf, n.Content[i+1], sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tomlValue(n *yaml.Node) (string, error) {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		parts := make([]string, len(n.Content))
+		for i, c := range n.Content {
+			s, err := tomlValue(c)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case yaml.MappingNode:
+		parts := make([]string, 0, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			s, err := tomlValue(n.Content[i+1])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, tomlKey(n.Content[i].Value)+" = "+s)
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	case yaml.AliasNode:
+		return tomlValue(n.Alias)
+	}
+	if n.Tag == "!!null" {
+		return "", fmt.Errorf("null values can't be written as TOML")
+	}
+	return scalarJSON(n), nil
+}
+
+// SetFile sets key to the JSON-encoded value in the config file at path.
+// JSON files are edited in place so formatting is untouched; YAML files keep
+// key order and comments; TOML files keep key order but lose comments.
+func SetFile(path, key string, value []byte) error {
+	format, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	if format == FormatJSON {
+		out, err = SetJSON(src, key, value)
+	} else {
+		out, err = setDocument(format, src, key, value)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return os.WriteFile(path, out, info.Mode().Perm())
+}
+
+func setDocument(format string, src []byte, key string, value []byte) ([]byte, error) {
+	// YAML is edited through its document node so comments survive
+	var doc yaml.Node
+	var root *yaml.Node
+	if format == FormatYAML {
+		if err := yaml.Unmarshal(src, &doc); err != nil {
+			return nil, err
+		}
+		if len(doc.Content) > 0 && doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("config file must contain an object")
+		}
+	}
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	} else {
+		var err error
+		if root, err = parseNode(format, src); err != nil {
+			return nil, err
+		}
+	}
+	var v any
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil, err
+	}
+	var n yaml.Node
+	if err := n.Encode(v); err != nil {
+		return nil, err
+	}
+	path := strings.Split(key, ".")
+	parent := root
+	for i, seg := range path[:len(path)-1] {
+		child := lookupNode(parent, seg)
+		if child != nil && child.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not an object", strings.Join(path[:i+1], "."))
+		}
+		parent = mappingAt(parent, []string{seg})
+	}
+	setNode(parent, path[len(path)-1], &n)
+
+	if len(doc.Content) > 0 {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&doc); err != nil {
+			return nil, err
+		}
+		_ = enc.Close()
+		return buf.Bytes(), nil
+	}
+	return encodeNode(format, root)
+}
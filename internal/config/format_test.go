@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const exampleJSON = `{
+  "app": {
+    "name": "shop",
+    "debug": true,
+    "language": "en-US"
+  },
+  "server": {
+    "port": 8080,
+    "cors": {
+      "allowed_origins": ["*"],
+      "allowed_methods": ["GET", "POST"]
+    }
+  },
+  "database": {
+    "type": "postgres",
+    "password": "env:KYGO_DATABASE_PASSWORD",
+    "params": {
+      "search_path": "app"
+    }
+  }
+}
+`
+
+func TestConvertRoundTrip(t *testing.T) {
+	want, err := Parse("config.json", []byte(exampleJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := Convert([]byte(exampleJSON), FormatJSON, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(canonical) != exampleJSON {
+		t.Errorf("JSON to JSON:\n%s\nwant:\n%s", canonical, exampleJSON)
+	}
+	for _, format := range []string{FormatYAML, FormatTOML} {
+		converted, err := Convert([]byte(exampleJSON), FormatJSON, format)
+		if err != nil {
+			t.Fatalf("to %s: %v", format, err)
+		}
+		got, err := Parse("config"+Extension(format), converted)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", format, err, converted)
+		}
+		if !reflect.DeepEqual(got.App, want.App) || !reflect.DeepEqual(got.Server, want.Server) || !reflect.DeepEqual(got.Database, want.Database) {
+			t.Errorf("%s decodes to a different config:\n%s", format, converted)
+		}
+		back, err := Convert(converted, format, FormatJSON)
+		if err != nil {
+			t.Fatalf("from %s: %v", format, err)
+		}
+		if string(back) != exampleJSON {
+			t.Errorf("JSON to %s and back:\n%s\nwant:\n%s", format, back, exampleJSON)
+		}
+	}
+}
+
+func TestSetFileKeepsOrder(t *testing.T) {
+	tests := []struct {
+		format string
+		in     string
+		want   string
+	}{
+		{
+			FormatJSON,
+			"{\n  \"zeta\": 1,\n  \"app\": {\n    \"name\": \"a\"\n  },\n  \"alpha\": 2\n}\n",
+			"{\n  \"zeta\": 1,\n  \"app\": {\n    \"name\": \"b\",\n    \"debug\": true\n  },\n  \"alpha\": 2\n}\n",
+		},
+		{
+			FormatYAML,
+			"zeta: 1\n# the application\napp:\n  name: a\nalpha: 2\n",
+			"zeta: 1\n# the application\napp:\n  name: b\n  debug: true\nalpha: 2\n",
+		},
+		{
+			FormatTOML,
+			"zeta = 1\nalpha = 2\n\n[app]\nname = \"a\"\n",
+			"zeta = 1\nalpha = 2\n\n[app]\nname = \"b\"\ndebug = true\n",
+		},
+	}
+	for _, tt := range tests {
+		p := filepath.Join(t.TempDir(), "config"+Extension(tt.format))
+		if err := os.WriteFile(p, []byte(tt.in), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetFile(p, "app.name", []byte(`"b"`)); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if err := SetFile(p, "app.debug", []byte(`true`)); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		got, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s:\n%s\nwant:\n%s", tt.format, got, tt.want)
+		}
+		if info, err := os.Stat(p); err == nil && info.Mode().Perm() != 0600 {
+			t.Errorf("%s: mode %v, want 0600", tt.format, info.Mode().Perm())
+		}
+	}
+}
+
+func TestSetFileRejectsScalarParent(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(p, []byte("app: shop\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFile(p, "app.name", []byte(`"b"`)); err == nil {
+		t.Error("SetFile under a string value succeeded")
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"gopkg.in/yaml.v3"
+)
+
+// GeneratedHeader marks Go files written by GenerateSection; only files
+// carrying it are overwritten when types are regenerated.
+const GeneratedHeader = "// Code generated by kygo config generate; DO NOT EDIT."
+
+// FrameworkSections are the top-level sections modelled by kyugo itself;
+// every other section of the example config belongs to the application.
+var FrameworkSections = []string{"app", "server", "database", "databases"}
+
+// IsFrameworkSection reports whether name is one of FrameworkSections.
+func IsFrameworkSection(name string) bool {
+	for _, s := range FrameworkSections {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AppSections returns the application-defined top-level sections of the
+// example config at path, in file order.
+func AppSections(path string) ([]string, error) {
+	root, err := readNode(path)
+	if err != nil {
+		return nil, err
+	}
+	var sections []string
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		name := root.Content[i].Value
+		if !IsFrameworkSection(name) && root.Content[i+1].Kind == yaml.MappingNode {
+			sections = append(sections, name)
+		}
+	}
+	return sections, nil
+}
+
+// Module and version generated YAML loaders import; the generated project
+// must require it.
+const (
+	YAMLModule  = "gopkg.in/yaml.v3"
+	YAMLVersion = "v3.0.1"
+)
+
+// RequireYAML adds YAMLModule to the go.mod in root as a direct
+// requirement unless it has one, so generated YAML loaders compile; go.sum
+// is left to `go mod tidy`. It reports whether go.mod changed.
+func RequireYAML(root string) (bool, error) {
+	p := filepath.Join(root, "go.mod")
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return false, err
+	}
+	f, err := modfile.Parse(p, b, nil)
+	if err != nil {
+		return false, err
+	}
+	reqs := []*modfile.Require{{Mod: module.Version{Path: YAMLModule, Version: YAMLVersion}}}
+	for _, r := range f.Require {
+		if r.Mod.Path != YAMLModule {
+			reqs = append(reqs, r)
+		} else if !r.Indirect {
+			return false, nil
+		}
+	}
+	f.SetRequireSeparateIndirect(reqs)
+	f.Cleanup()
+	out, err := f.Format()
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(p, out, 0644)
+}
+
+// GenerateSection returns a Go source file (package config) declaring a
+// typed struct for the named section of the example config at path, plus a
+// Load<Section> function reading that section from a config file. Field
+// types are inferred from the example values.
+func GenerateSection(path, section string) ([]byte, error) {
+	fileFormat, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	if fileFormat == FormatTOML {
+		return nil, fmt.Errorf("Go types can only be generated from JSON or YAML config files")
+	}
+	root, err := readNode(path)
+	if err != nil {
+		return nil, err
+	}
+	node := lookupNode(root, section)
+	if node == nil {
+		return nil, fmt.Errorf("section %q not found in %s", section, filepath.Base(path))
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("section %q in %s is not an object", section, filepath.Base(path))
+	}
+
+	typeName := GoName(section) + "Config"
+	decoder := "json"
+	if fileFormat == FormatYAML {
+		decoder = "yaml"
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n// Source: %s\n\npackage config\n\n", GeneratedHeader, filepath.Base(path))
+	if fileFormat == FormatYAML {
+		fmt.Fprintf(&buf, "import (\n\t\"os\"\n\n\t%q\n)\n\n", YAMLModule)
+	} else {
+		buf.WriteString("import (\n\t\"encoding/json\"\n\t\"os\"\n)\n\n")
+	}
+	fmt.Fprintf(&buf, "// %s mirrors the %q section of the config file.\n", typeName, section)
+	fmt.Fprintf(&buf, "type %s ", typeName)
+	writeStruct(&buf, node, decoder)
+	buf.WriteString("\n\n")
+
+	fmt.Fprintf(&buf, "// Load%s reads the %q section from the config file at path.\n", GoName(section), section)
+	fmt.Fprintf(&buf, "func Load%s(path string) (*%s, error) {\n", GoName(section), typeName)
+	buf.WriteString("\tb, err := os.ReadFile(path)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(&buf, "\tvar doc struct {\n\t\tSection %s `%s:%q`\n\t}\n", typeName, decoder, section)
+	fmt.Fprintf(&buf, "\tif err := %s.Unmarshal(b, &doc); err != nil {\n\t\treturn nil, err\n\t}\n", decoder)
+	buf.WriteString("\treturn &doc.Section, nil\n}\n")
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return out, nil
+}
+
+// writeStruct writes a struct type for a mapping node, tagging fields for
+// the json or yaml decoder.
+func writeStruct(buf *bytes.Buffer, n *yaml.Node, tag string) {
+	buf.WriteString("struct {\n")
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i].Value
+		fmt.Fprintf(buf, "%s ", GoName(key))
+		writeType(buf, n.Content[i+1], tag)
+		fmt.Fprintf(buf, " `%s:%q`\n", tag, key)
+	}
+	buf.WriteString("}")
+}
+
+// writeType writes the Go type inferred from an example value.
+func writeType(buf *bytes.Buffer, n *yaml.Node, tag string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		writeStruct(buf, n, tag)
+	case yaml.SequenceNode:
+		buf.WriteString("[]")
+		if len(n.Content) == 0 {
+			buf.WriteString("any")
+			return
+		}
+		writeType(buf, n.Content[0], tag)
+	case yaml.AliasNode:
+		writeType(buf, n.Alias, tag)
+	default:
+		switch n.Tag {
+		case "!!str":
+			buf.WriteString("string")
+		case "!!int":
+			buf.WriteString("int")
+		case "!!float":
+			buf.WriteString("float64")
+		case "!!bool":
+			buf.WriteString("bool")
+		default:
+			buf.WriteString("any")
+		}
+	}
+}
+
+// initialisms are written in upper case in generated Go names.
+var initialisms = map[string]bool{
+	"API": true, "CORS": true, "DB": true, "DSN": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "JWT": true, "SQL": true, "SSL": true, "TLS": true, "TTL": true,
+	"UI": true, "URL": true, "URI": true, "UUID": true,
+}
+
+// GoName converts a config key such as "webhook_url" into an exported Go
+// identifier ("WebhookURL").
+func GoName(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.'
+	})
+	for i, p := range parts {
+		if initialisms[strings.ToUpper(p)] {
+			parts[i] = strings.ToUpper(p)
+			continue
+		}
+		parts[i] = strings.ToUpper(p[:1]) + p[1:]
+	}
+	name := strings.Join(parts, "")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "X" + name
+	}
+	return name
+}
+
+// readNode reads a config file into an ordered mapping node.
+func readNode(path string) (*yaml.Node, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fileFormat, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseNode(fileFormat, b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return root, nil
+}
+
+// WriteGenerated writes generated code to path unless the existing file was
+// written by hand (it lacks GeneratedHeader).
+func WriteGenerated(path string, code []byte) error {
+	if b, err := os.ReadFile(path); err == nil && !bytes.HasPrefix(b, []byte(GeneratedHeader)) {
+		return fmt.Errorf("%s was not generated by kygo; refusing to overwrite it", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, code, 0644)
+}
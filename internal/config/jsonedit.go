@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// member is a key/value pair of a JSON object located in the source text.
+type member struct {
+	key        string
+	keyStart   int
+	valueStart int
+	valueEnd   int
+}
+
+// SetJSON sets the value at path (dotted keys) in the JSON document src to
+// the JSON-encoded value, rewriting only the affected bytes so key order,
+// indentation and the rest of the formatting are preserved. Missing objects
+// along the path are created.
+func SetJSON(src []byte, key string, value []byte) ([]byte, error) {
+	s := string(src)
+	start := skipSpace(s, 0)
+	if start >= len(s) || s[start] != '{' {
+		return nil, fmt.Errorf("config file must contain a JSON object")
+	}
+	path := strings.Split(key, ".")
+	unit := indentUnit(s, start)
+	if v := bytes.TrimSpace(value); len(v) > 0 && v[0] == '{' {
+		// lay objects out like the rest of the file
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, v, "", unit); err == nil {
+			value = buf.Bytes()
+		}
+	}
+
+	obj := start
+	for i, seg := range path {
+		members, end, err := parseObject(s, obj)
+		if err != nil {
+			return nil, err
+		}
+		var found *member
+		for j := range members {
+			if members[j].key == seg {
+				found = &members[j]
+			}
+		}
+		if found == nil {
+			// build the remaining path as nested objects and insert it
+			v := string(value)
+			for k := len(path) - 1; k > i; k-- {
+				v = "{\n" + unit + quote(path[k]) + ": " + indentLines(v, unit) + "\n}"
+			}
+			return []byte(insertMember(s, obj, end, members, unit, quote(seg)+": "+v)), nil
+		}
+		if i == len(path)-1 {
+			return []byte(s[:found.valueStart] + string(value) + s[found.valueEnd:]), nil
+		}
+		if s[found.valueStart] != '{' {
+			return nil, fmt.Errorf("%s is not an object", strings.Join(path[:i+1], "."))
+		}
+		obj = found.valueStart
+	}
+	return src, nil
+}
+
+// insertMember adds text as the last member of the object starting at obj
+// and closing at end, following the indentation of its existing members.
+func insertMember(s string, obj, end int, members []member, unit, text string) string {
+	if len(members) == 0 {
+		outer := lineIndent(s, obj)
+		return s[:obj+1] + "\n" + outer + unit + indentLines(text, outer+unit) + "\n" + outer + s[end:]
+	}
+	last := members[len(members)-1]
+	indent := lineIndent(s, last.keyStart)
+	if !startsLine(s, last.keyStart) {
+		return s[:last.valueEnd] + ", " + text + s[last.valueEnd:]
+	}
+	return s[:last.valueEnd] + ",\n" + indent + indentLines(text, indent) + s[last.valueEnd:]
+}
+
+// indentLines prefixes every line after the first with indent.
+func indentLines(text, indent string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+indent)
+}
+
+// parseObject returns the members of the object starting at s[start] and the
+// offset of its closing brace.
+func parseObject(s string, start int) ([]member, int, error) {
+	var members []member
+	i := skipSpace(s, start+1)
+	if i < len(s) && s[i] == '}' {
+		return nil, i, nil
+	}
+	for i < len(s) {
+		if s[i] != '"' {
+			return nil, 0, fmt.Errorf("invalid JSON at offset %d: expected object key", i)
+		}
+		keyEnd, err := skipString(s, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		var key string
+		if err := json.Unmarshal([]byte(s[i:keyEnd]), &key); err != nil {
+			return nil, 0, err
+		}
+		j := skipSpace(s, keyEnd)
+		if j >= len(s) || s[j] != ':' {
+			return nil, 0, fmt.Errorf("invalid JSON at offset %d: expected ':'", j)
+		}
+		vs := skipSpace(s, j+1)
+		ve, err := skipValue(s, vs)
+		if err != nil {
+			return nil, 0, err
+		}
+		members = append(members, member{key: key, keyStart: i, valueStart: vs, valueEnd: ve})
+		i = skipSpace(s, ve)
+		if i < len(s) && s[i] == ',' {
+			i = skipSpace(s, i+1)
+			continue
+		}
+		if i < len(s) && s[i] == '}' {
+			return members, i, nil
+		}
+		return nil, 0, fmt.Errorf("invalid JSON at offset %d: expected ',' or '}'", i)
+	}
+	return nil, 0, fmt.Errorf("invalid JSON: unexpected end of input")
+}
+
+// skipValue returns the offset just past the JSON value starting at s[i].
+func skipValue(s string, i int) (int, error) {
+	if i >= len(s) {
+		return 0, fmt.Errorf("invalid JSON: unexpected end of input")
+	}
+	switch s[i] {
+	case '"':
+		return skipString(s, i)
+	case '{', '[':
+		depth := 0
+		for j := i; j < len(s); j++ {
+			switch s[j] {
+			case '"':
+				end, err := skipString(s, j)
+				if err != nil {
+					return 0, err
+				}
+				j = end - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return j + 1, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("invalid JSON: unterminated value at offset %d", i)
+	default:
+		j := i
+		for j < len(s) && !strings.ContainsRune(",}] \t\r\n", rune(s[j])) {
+			j++
+		}
+		return j, nil
+	}
+}
+
+// skipString returns the offset just past the JSON string starting at s[i].
+func skipString(s string, i int) (int, error) {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid JSON: unterminated string at offset %d", i)
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && strings.ContainsRune(" \t\r\n", rune(s[i])) {
+		i++
+	}
+	return i
+}
+
+// lineIndent returns the whitespace at the start of the line containing i.
+func lineIndent(s string, i int) string {
+	ls := strings.LastIndex(s[:i], "\n") + 1
+	j := ls
+	for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
+		j++
+	}
+	return s[ls:j]
+}
+
+// startsLine reports whether only whitespace precedes i on its line.
+func startsLine(s string, i int) bool {
+	ls := strings.LastIndex(s[:i], "\n") + 1
+	return strings.TrimSpace(s[ls:i]) == ""
+}
+
+// indentUnit guesses the file's indentation step from its first member.
+func indentUnit(s string, start int) string {
+	members, _, err := parseObject(s, start)
+	if err == nil && len(members) > 0 && startsLine(s, members[0].keyStart) {
+		if u := lineIndent(s, members[0].keyStart); u != "" {
+			return u
+		}
+	}
+	return "  "
+}
+
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaJSON is the JSON Schema describing config.json. It is the reference
+// for both `config validate` and the Config struct (see CheckStruct).
+//
+//go:embed schema.json
+var SchemaJSON []byte
+
+// schema is the subset of JSON Schema (draft-07) used by schema.json.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	Definitions          map[string]*schema `json:"definitions"`
+}
+
+var rootSchema = mustParseSchema(SchemaJSON)
+
+func mustParseSchema(b []byte) *schema {
+	var s schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		panic(fmt.Sprintf("config: invalid schema.json: %v", err))
+	}
+	return &s
+}
+
+// resolve follows a local "#/definitions/<name>" reference.
+func (s *schema) resolve() *schema {
+	if s == nil || s.Ref == "" {
+		return s
+	}
+	name := strings.TrimPrefix(s.Ref, "#/definitions/")
+	return rootSchema.Definitions[name]
+}
+
+// additional returns the schema for keys not listed in Properties and
+// whether such keys are allowed at all.
+func (s *schema) additional() (*schema, bool) {
+	raw := strings.TrimSpace(string(s.AdditionalProperties))
+	switch raw {
+	case "", "true":
+		return nil, true
+	case "false":
+		return nil, false
+	}
+	var as schema
+	if err := json.Unmarshal(s.AdditionalProperties, &as); err != nil {
+		return nil, true
+	}
+	return &as, true
+}
+
+// validateSchema checks v against s. Keys the schema does not allow are
+// reported as warnings so custom sections don't fail validation.
+func validateSchema(v any, s *schema, key string) []Issue {
+	s = s.resolve()
+	if s == nil {
+		return nil
+	}
+	if !matchesType(v, s.Type) {
+		return []Issue{{Key: key, Message: fmt.Sprintf("expected %s, got %s", s.Type, jsonType(v))}}
+	}
+	var issues []Issue
+	if len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+		issues = append(issues, Issue{Key: key, Message: fmt.Sprintf("must be one of %s, got %v", formatEnum(s.Enum), v)})
+	}
+	switch n := v.(type) {
+	case float64:
+		if s.Minimum != nil && n < *s.Minimum {
+			issues = append(issues, Issue{Key: key, Message: fmt.Sprintf("must be at least %v, got %v", *s.Minimum, n)})
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			issues = append(issues, Issue{Key: key, Message: fmt.Sprintf("must be at most %v, got %v", *s.Maximum, n)})
+		}
+	case string:
+		if s.MinLength != nil && len(n) < *s.MinLength {
+			issues = append(issues, Issue{Key: key, Message: "must not be empty"})
+		}
+	case []any:
+		for i, e := range n {
+			issues = append(issues, validateSchema(e, s.Items, fmt.Sprintf("%s[%d]", key, i))...)
+		}
+	case map[string]any:
+		for _, r := range s.Required {
+			if _, ok := n[r]; !ok {
+				issues = append(issues, Issue{Key: joinKey(key, r), Message: "is required"})
+			}
+		}
+		extra, allowed := s.additional()
+		for _, k := range sortedKeys(n) {
+			if ps, ok := s.Properties[k]; ok {
+				issues = append(issues, validateSchema(n[k], ps, joinKey(key, k))...)
+				continue
+			}
+			if !allowed {
+				issues = append(issues, Issue{Key: joinKey(key, k), Message: "unknown key", Warning: true})
+				continue
+			}
+			issues = append(issues, validateSchema(n[k], extra, joinKey(key, k))...)
+		}
+	}
+	return issues
+}
+
+func matchesType(v any, t string) bool {
+	switch t {
+	case "":
+		return true
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == float64(int64(n))
+	}
+	return false
+}
+
+func inEnum(v any, enum []any) bool {
+	for _, e := range enum {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []any) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		parts[i] = fmt.Sprint(e)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CheckStruct reports every difference between schema.json and the Config
+// struct, so the two can't drift apart silently.
+func CheckStruct() []string {
+	problems := checkStruct(rootSchema, reflect.TypeOf(Config{}), "")
+
+	db := rootSchema.Definitions["database"]
+	if db != nil && db.Properties["type"] != nil {
+		var enum []string
+		for _, e := range db.Properties["type"].Enum {
+			enum = append(enum, fmt.Sprint(e))
+		}
+		if strings.Join(enum, ",") != strings.Join(SupportedDatabaseTypes, ",") {
+			problems = append(problems, fmt.Sprintf("database.type: schema enum [%s] differs from SupportedDatabaseTypes [%s]",
+				strings.Join(enum, ", "), strings.Join(SupportedDatabaseTypes, ", ")))
+		}
+	}
+	return problems
+}
+
+func checkStruct(s *schema, t reflect.Type, key string) []string {
+	s = s.resolve()
+	if s == nil {
+		return []string{fmt.Sprintf("%s: missing from schema", key)}
+	}
+	label := key
+	if label == "" {
+		label = "(root)"
+	}
+	mismatch := func() []string {
+		return []string{fmt.Sprintf("%s: schema type %q does not match Go type %s", label, s.Type, t)}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if s.Type != "object" {
+			return mismatch()
+		}
+		var problems []string
+		fields := jsonFields(t)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ps, ok := s.Properties[name]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: field %s is not in the schema", joinKey(key, name), fields[name].Name))
+				continue
+			}
+			problems = append(problems, checkStruct(ps, fields[name].Type, joinKey(key, name))...)
+		}
+		props := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			props = append(props, name)
+		}
+		sort.Strings(props)
+		for _, name := range props {
+			if _, ok := fields[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: in the schema but missing from the Config struct", joinKey(key, name)))
+			}
+		}
+		return problems
+	case reflect.Map:
+		extra, _ := s.additional()
+		if s.Type != "object" || extra == nil {
+			return mismatch()
+		}
+		return checkStruct(extra, t.Elem(), joinKey(key, "*"))
+	case reflect.Slice:
+		if s.Type != "array" {
+			return mismatch()
+		}
+		return checkStruct(s.Items, t.Elem(), key+"[]")
+	case reflect.String:
+		if s.Type != "string" {
+			return mismatch()
+		}
+	case reflect.Bool:
+		if s.Type != "boolean" {
+			return mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s.Type != "integer" {
+			return mismatch()
+		}
+	case reflect.Float32, reflect.Float64:
+		if s.Type != "number" {
+			return mismatch()
+		}
+	}
+	return nil
+}
+
+// flattenKeys returns the dotted keys of every leaf value in raw. Arrays are
+// treated as leaves.
+func flattenKeys(raw map[string]any, prefix string) []string {
+	var keys []string
+	for _, k := range sortedKeys(raw) {
+		key := joinKey(prefix, k)
+		if m, ok := raw[k].(map[string]any); ok && len(m) > 0 {
+			keys = append(keys, flattenKeys(m, key)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// SyncReport lists the keys that differ between config.example.json and
+// config.json.
+type SyncReport struct {
+	MissingFromConfig  []string // in the example but not in config.json
+	MissingFromExample []string // in config.json but not in the example
+}
+
+// Sync compares the keys of the example file with those of the config file.
+func Sync(examplePath, configPath string) (*SyncReport, error) {
+	example, err := readFile(examplePath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := readFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	exampleKeys := map[string]bool{}
+	for _, k := range flattenKeys(example, "") {
+		exampleKeys[k] = true
+	}
+	configKeys := map[string]bool{}
+	for _, k := range flattenKeys(cfg, "") {
+		configKeys[k] = true
+	}
+	var r SyncReport
+	for _, k := range flattenKeys(example, "") {
+		if !configKeys[k] {
+			r.MissingFromConfig = append(r.MissingFromConfig, k)
+		}
+	}
+	for _, k := range flattenKeys(cfg, "") {
+		if !exampleKeys[k] {
+			r.MissingFromExample = append(r.MissingFromExample, k)
+		}
+	}
+	return &r, nil
+}
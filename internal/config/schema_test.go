@@ -0,0 +1,40 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCheckStruct fails when schema.json and the Config struct drift apart;
+// update both when adding a key.
+func TestCheckStruct(t *testing.T) {
+	for _, p := range CheckStruct() {
+		t.Error(p)
+	}
+}
+
+func TestCheckStructReportsDrift(t *testing.T) {
+	s := mustParseSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"port": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"removed": {"type": "boolean"}
+		}
+	}`))
+	type drifted struct {
+		Name  string   `json:"name"`
+		Port  int      `json:"port"`
+		Tags  []string `json:"tags"`
+		Added bool     `json:"added"`
+	}
+	want := []string{
+		"added: field Added is not in the schema",
+		`port: schema type "string" does not match Go type int`,
+		"removed: in the schema but missing from the Config struct",
+	}
+	if got := checkStruct(s, reflect.TypeOf(drifted{}), ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("checkStruct:\n got %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Prefixes of string values that reference a secret instead of holding it.
+const (
+	refEnv  = "env:"
+	refFile = "file:"
+)
+
+// IsReference reports whether s is an env: or file: secret reference.
+func IsReference(s string) bool {
+	return strings.HasPrefix(s, refEnv) || strings.HasPrefix(s, refFile)
+}
+
+// resolveReferences replaces every string value of the form env:NAME or
+// file:PATH with the referenced secret, remembering the reference so it can
+// be shown instead of the secret. Relative file paths are resolved against
+// the config file's directory. A reference that can't be resolved leaves
+// the value empty and is reported by Resolved, so only commands that use
+// the value fail.
+func (c *Config) resolveReferences(dotenv map[string]string) error {
+	return walk(reflect.ValueOf(c).Elem(), "", func(key string, v reflect.Value) error {
+		if v.Kind() != reflect.String || !IsReference(v.String()) {
+			return nil
+		}
+		ref := v.String()
+		var value string
+		switch {
+		case strings.HasPrefix(ref, refEnv):
+			name := strings.TrimPrefix(ref, refEnv)
+			if _, ok := os.LookupEnv(name); !ok {
+				if _, ok := dotenv[name]; !ok {
+					c.unresolved(key, ref, v, fmt.Errorf("environment variable %s is not set", name))
+					return nil
+				}
+			}
+			value = lookupEnv(name, dotenv)
+		case strings.HasPrefix(ref, refFile):
+			p := strings.TrimPrefix(ref, refFile)
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(filepath.Dir(c.path), p)
+			}
+			b, err := os.ReadFile(p)
+			if err != nil {
+				c.unresolved(key, ref, v, err)
+				return nil
+			}
+			value = strings.TrimRight(string(b), "\r\n")
+		}
+		v.SetString(value)
+		c.refs[key] = ref
+		return nil
+	})
+}
+
+// unresolved records a reference that can't be resolved.
+func (c *Config) unresolved(key, ref string, v reflect.Value, err error) {
+	v.SetString("")
+	c.refs[key] = ref
+	c.missing[key] = fmt.Errorf("%s: cannot resolve %s: %w", key, ref, err)
+}
+
+// Resolved returns an error when a secret reference at key, or below it,
+// could not be resolved. An empty key checks the whole config.
+func (c *Config) Resolved(key string) error {
+	keys := make([]string, 0, len(c.missing))
+	for k := range c.missing {
+		if key == "" || k == key || strings.HasPrefix(k, key+".") {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	return c.missing[keys[0]]
+}
+
+// Reference returns the env: or file: reference key was resolved from, or
+// "" when its value was given directly.
+func (c *Config) Reference(key string) string {
+	return c.refs[key]
+}
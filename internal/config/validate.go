@@ -0,0 +1,248 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SupportedDatabaseTypes lists the database.type values DatabaseURL knows.
+var SupportedDatabaseTypes = []string{"postgres", "pg", "postgresql", "mysql", "mariadb", "sqlite", "sqlite3"}
+
+// Issue is a single problem found while validating a config file.
+type Issue struct {
+	Key     string
+	Message string
+	Warning bool
+}
+
+func (i Issue) String() string {
+	if i.Key == "" {
+		return i.Message
+	}
+	return i.Key + ": " + i.Message
+}
+
+// Validate checks the config file at path (merged with its environment
+// overlay and env overrides) against schema.json, then applies the checks
+// the schema can't express.
+func Validate(path string) ([]Issue, error) {
+	cfg, err := Load(path)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		// the file can't be decoded into Config; report what the schema
+		// says about the raw document instead
+		if path == "" {
+			path = Discover("")
+		}
+		raw, rerr := readFile(path)
+		if rerr != nil {
+			return nil, err
+		}
+		return validateSchema(raw, rootSchema, ""), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	issues := validateSchema(cfg.effectiveDocument(), rootSchema, "")
+	if cfg.Database.Type != "" {
+		issues = append(issues, checkDatabase("database", cfg.Database)...)
+	}
+	names := make([]string, 0, len(cfg.Databases))
+	for name := range cfg.Databases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		issues = append(issues, checkDatabase("databases."+name, cfg.Databases[name])...)
+	}
+	return issues, nil
+}
+
+// effectiveDocument returns the merged file contents with env overrides
+// applied, keeping keys that the Config struct doesn't model.
+func (c *Config) effectiveDocument() map[string]any {
+	doc := map[string]any{}
+	if c.raw != nil {
+		b, _ := json.Marshal(c.raw)
+		_ = json.Unmarshal(b, &doc)
+	}
+	_ = walk(reflect.ValueOf(c).Elem(), "", func(key string, v reflect.Value) error {
+		switch c.sources[key] {
+		case SourceEnv, SourceDotEnv, "--env", "KYGO_ENV":
+		default:
+			return nil
+		}
+		var value any
+		b, _ := json.Marshal(v.Interface())
+		_ = json.Unmarshal(b, &value)
+		setPath(doc, strings.Split(key, "."), value)
+		return nil
+	})
+	return doc
+}
+
+// setPath stores value under path in doc, creating objects as needed.
+func setPath(doc map[string]any, path []string, value any) {
+	for _, seg := range path[:len(path)-1] {
+		next, ok := doc[seg].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			doc[seg] = next
+		}
+		doc = next
+	}
+	doc[path[len(path)-1]] = value
+}
+
+// checkDatabase covers connection rules schema.json doesn't encode.
+func checkDatabase(prefix string, db Database) []Issue {
+	if db.Type == "" || strings.HasPrefix(db.Type, "sqlite") {
+		return nil
+	}
+	if db.Host == "" {
+		return []Issue{{Key: prefix + ".host", Message: "is required for " + db.Type}}
+	}
+	return nil
+}
+
+// fieldType returns the Go type stored under the dotted key, or nil when
+// the key isn't part of the Config schema.
+func fieldType(key string) reflect.Type {
+	t := reflect.TypeOf(Config{})
+	for _, seg := range strings.Split(key, ".") {
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := jsonFields(t)[seg]
+			if !ok {
+				return nil
+			}
+			t = f.Type
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	return t
+}
+
+// schemaAt returns the schema for the dotted key, or nil when unknown.
+func schemaAt(key string) *schema {
+	s := rootSchema
+	for _, seg := range strings.Split(key, ".") {
+		s = s.resolve()
+		if ps, ok := s.Properties[seg]; ok {
+			s = ps
+			continue
+		}
+		extra, _ := s.additional()
+		if extra == nil {
+			return nil
+		}
+		s = extra
+	}
+	return s
+}
+
+// encodeValue converts a command-line value into JSON for key, using the
+// schema type when the key is known and guessing otherwise.
+func encodeValue(key, value string) ([]byte, error) {
+	t := fieldType(key)
+	if t == nil {
+		if json.Valid([]byte(value)) {
+			return []byte(value), nil
+		}
+		return json.Marshal(value)
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(value), &obj); err != nil {
+			return nil, fmt.Errorf("%s expects a JSON object", key)
+		}
+		for _, i := range validateSchema(obj, schemaAt(key), key) {
+			if !i.Warning {
+				return nil, fmt.Errorf("%s", i)
+			}
+		}
+		return []byte(value), nil
+	case reflect.Slice:
+		if strings.HasPrefix(strings.TrimSpace(value), "[") {
+			var arr []any
+			if err := json.Unmarshal([]byte(value), &arr); err != nil {
+				return nil, fmt.Errorf("%s expects a JSON array or comma-separated list", key)
+			}
+			return []byte(value), nil
+		}
+	}
+	v := reflect.New(t).Elem()
+	if err := setFromString(v, value); err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	return json.Marshal(v.Interface())
+}
+
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			fields[name] = t.Field(i)
+		}
+	}
+	return fields
+}
+
+// jsonName returns the JSON key of a struct field, or "" when the field is
+// not part of the JSON document.
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -5,14 +5,19 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"go/format"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/project"
 	"github.com/go-kyugo/kygo/internal/ui"
 )
 
@@ -42,19 +47,13 @@ var CreateCmd = &cobra.Command{
 		kind := args[0]
 		name := args[1]
 
-		cwd, err := os.Getwd()
+		root := project.Root()
+		proj, err := loadProject(root)
 		if err != nil {
 			return err
 		}
 
-		module := "github.com/go-kyugo/kygo"
-		root := cwd
-		modPath := filepath.Join(root, "go.mod")
-		if _, err := os.Stat(modPath); os.IsNotExist(err) {
-			root = filepath.Dir(root)
-		}
-
-		if err := Generate(root, module, kind, name); err != nil {
+		if err := Generate(root, proj, kind, name); err != nil {
 			return err
 		}
 		ui.Successf("Created %s %s", kind, name)
@@ -70,19 +69,13 @@ func CreateKindCmd(kind string) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 
-			cwd, err := os.Getwd()
+			root := project.Root()
+			proj, err := loadProject(root)
 			if err != nil {
 				return err
 			}
 
-			module := "kyugo.dev/kyugo-cli/v1"
-			root := cwd
-			modPath := filepath.Join(root, "go.mod")
-			if _, err := os.Stat(modPath); os.IsNotExist(err) {
-				root = filepath.Dir(root)
-			}
-
-			if err := Generate(root, module, kind, name); err != nil {
+			if err := Generate(root, proj, kind, name); err != nil {
 				return err
 			}
 			ui.Successf("Created %s %s", kind, name)
@@ -95,7 +88,9 @@ func init() {
 	tmpl = template.Must(template.ParseFS(templatesFS, "templates/*.gotmpl"))
 }
 
-func Generate(root, module, kind, name string) error {
+// Generate writes the artefact kind named name into the project at root,
+// laid out as described by proj.
+func Generate(root string, proj *project.Project, kind, name string) error {
 	n := sanitizeName(name)
 	data := struct {
 		Name       string
@@ -103,12 +98,14 @@ func Generate(root, module, kind, name string) error {
 		FuncName   string
 		ModelName  string
 		Table      string
+		Dialect    string
 	}{
 		Name:       n,
 		StructName: toPascal(n),
 		FuncName:   toLowerFirst(toPascal(n) + "Controller"),
 		ModelName:  toPascal(n),
 		Table:      toSnake(n),
+		Dialect:    proj.Dialect,
 	}
 
 	var tplName string
@@ -131,7 +128,11 @@ func Generate(root, module, kind, name string) error {
 		filename = n + ".go"
 	case "migration":
 		tplName = "migration.gotmpl"
+		dir := kindDir(proj, kind, n)
 		ts := time.Now().Format("20060102150405")
+		if proj.Generators.MigrationVersion == project.VersionSequence {
+			ts = nextSequence(filepath.Join(root, dir))
+		}
 		// use .up.sql / .down.sql suffixes to be compatible with golang-migrate
 		upFilename := fmt.Sprintf("%s_%s.up.sql", ts, toSnake(n))
 		downFilename := fmt.Sprintf("%s_%s.down.sql", ts, toSnake(n))
@@ -159,7 +160,6 @@ func Generate(root, module, kind, name string) error {
 			}
 		}
 
-		dir := kindDir(kind, n)
 		if err := writeFile(root, dir, upFilename, upBuf.Bytes()); err != nil {
 			return err
 		}
@@ -176,6 +176,8 @@ func Generate(root, module, kind, name string) error {
 	case "validation":
 		tplName = "validation.gotmpl"
 		filename = n + ".go"
+	case "config":
+		return generateConfigSection(root, kindDir(proj, kind, n), n)
 	default:
 		return errors.New("unknown generate type: " + kind)
 	}
@@ -185,14 +187,14 @@ func Generate(root, module, kind, name string) error {
 		return err
 	}
 
-	dir := kindDir(kind, n)
+	dir := kindDir(proj, kind, n)
 	if err := writeFile(root, dir, filename, buf.Bytes()); err != nil {
 		return err
 	}
 
-	// If we just created a controller, ensure it's registered in http/route/route.go
-	if kind == "controller" {
-		routePath := filepath.Join(root, "http", "route", "route.go")
+	// If we just created a controller, ensure it's registered in the route file
+	if kind == "controller" && proj.Generators.RegisterControllers {
+		routePath := filepath.Join(root, filepath.FromSlash(proj.Dirs.Route), "route.go")
 		if _, err := os.Stat(routePath); err == nil {
 			b, err := os.ReadFile(routePath)
 			if err == nil {
@@ -201,13 +203,13 @@ func Generate(root, module, kind, name string) error {
 				// If the file already references the controller's NewController(), do nothing
 				if !strings.Contains(s, data.Name+".NewController()") {
 					// Ensure import for the controller package exists
-					importPath := module + "/http/controller/" + data.Name
+					importPath := path.Join(proj.Module, filepath.ToSlash(dir))
 					if !strings.Contains(s, "\""+importPath+"\"") {
 						if impIdx := strings.Index(s, "import ("); impIdx != -1 {
 							// find end of import block
 							rest := s[impIdx:]
 							if impEnd := strings.Index(rest, ")"); impEnd != -1 {
-								newImportBlock := rest[:impEnd] + "\n\t\"" + importPath + "\"" + rest[impEnd:]
+								newImportBlock := strings.TrimRight(rest[:impEnd], " \t\n") + "\n\t\"" + importPath + "\"\n" + rest[impEnd:]
 								s = s[:impIdx] + newImportBlock + s[impIdx+len(rest):]
 							}
 						} else if singleImpIdx := strings.Index(s, "import \""); singleImpIdx != -1 {
@@ -251,8 +253,12 @@ func Generate(root, module, kind, name string) error {
 						}
 					}
 
-					// write back modified route.go
-					_ = os.WriteFile(routePath, []byte(s), 0644)
+					// write back modified route.go, gofmt'd when it parses
+					out := []byte(s)
+					if formatted, err := format.Source(out); err == nil {
+						out = formatted
+					}
+					_ = os.WriteFile(routePath, out, 0644)
 				}
 			}
 		}
@@ -261,6 +267,90 @@ func Generate(root, module, kind, name string) error {
 	return nil
 }
 
+// loadProject reads the project's kygo.json. The module path prefixes the
+// import paths written into generated code, so it must be known.
+func loadProject(root string) (*project.Project, error) {
+	proj, err := project.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	if proj.Module == "" {
+		return nil, fmt.Errorf("no module path found in %s or %s", filepath.Join(root, project.FileName), filepath.Join(root, "go.mod"))
+	}
+	return proj, nil
+}
+
+// nextSequence returns the version following the highest numbered migration
+// in dir, zero-padded like 000001.
+func nextSequence(dir string) string {
+	last := 0
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.Atoi(prefix); err == nil && v > last {
+			last = v
+		}
+	}
+	return fmt.Sprintf("%06d", last+1)
+}
+
+// generateConfigSection adds an application section to the example config
+// and generates its typed Go struct and loader.
+func generateConfigSection(root, dir, name string) error {
+	example := config.DiscoverExample(root)
+	if _, err := os.Stat(example); err != nil {
+		return fmt.Errorf("no example config found in %s: %w", root, err)
+	}
+	if config.IsFrameworkSection(name) {
+		return fmt.Errorf("%q is a kyugo config section; pick another name", name)
+	}
+	sections, err := config.AppSections(example)
+	if err != nil {
+		return err
+	}
+	for _, s := range sections {
+		if s == name {
+			return fmt.Errorf("section %q already exists in %s", name, example)
+		}
+	}
+	// check up front so an unsupported format leaves the example untouched
+	fileFormat, err := config.FormatOf(example)
+	if err != nil || fileFormat == config.FormatTOML {
+		return fmt.Errorf("create config needs a JSON or YAML example config, found %s", filepath.Base(example))
+	}
+	original, err := os.ReadFile(example)
+	if err != nil {
+		return err
+	}
+	if err := config.SetFile(example, name, []byte(`{"enabled": false}`)); err != nil {
+		return err
+	}
+	code, err := config.GenerateSection(example, name)
+	if err == nil {
+		err = writeFile(root, dir, name+".go", code)
+	}
+	if err != nil {
+		// leave the example as it was when the Go file can't be written
+		if rerr := os.WriteFile(example, original, 0644); rerr != nil {
+			return fmt.Errorf("%w (restoring %s: %v)", err, example, rerr)
+		}
+		return err
+	}
+	if fileFormat == config.FormatYAML {
+		added, err := config.RequireYAML(root)
+		if err != nil {
+			return err
+		}
+		if added {
+			ui.Info(fmt.Sprintf("Added %s to go.mod; run `go mod tidy` to update go.sum", config.YAMLModule))
+		}
+	}
+	return nil
+}
+
 func sanitizeName(s string) string {
 	s = strings.TrimSpace(s)
 	s = strings.ReplaceAll(s, "-", "_")
@@ -321,26 +411,31 @@ func toLowerFirst(s string) string {
 	return strings.ToLower(s[:1]) + s[1:]
 }
 
-func kindDir(kind, name string) string {
+// kindDir returns the directory, relative to the project root, that holds
+// artefacts of kind.
+func kindDir(proj *project.Project, kind, name string) string {
+	d := proj.Dirs
 	switch kind {
 	case "controller":
-		return filepath.Join("http", "controller", name)
+		return filepath.Join(filepath.FromSlash(d.Controller), name)
 	case "model":
-		return filepath.Join("database", "model")
+		return filepath.FromSlash(d.Model)
 	case "repository":
-		return filepath.Join("database", "repository")
+		return filepath.FromSlash(d.Repository)
 	case "service":
-		return filepath.Join("services", name)
+		return filepath.Join(filepath.FromSlash(d.Service), name)
 	case "middleware":
-		return filepath.Join("http", "middleware")
+		return filepath.FromSlash(d.Middleware)
 	case "migration":
-		return filepath.Join("database", "migrations")
+		return filepath.FromSlash(d.Migrations)
 	case "seed":
-		return filepath.Join("database", "seed")
+		return filepath.FromSlash(d.Seed)
 	case "dto":
-		return "dto"
+		return filepath.FromSlash(d.DTO)
 	case "validation":
-		return filepath.Join("http", "validation")
+		return filepath.FromSlash(d.Validation)
+	case "config":
+		return filepath.FromSlash(d.Config)
 	default:
 		return ""
 	}
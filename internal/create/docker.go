@@ -0,0 +1,53 @@
+package create
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/go-kyugo/kygo/internal/docker"
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// CreateDockerCmd returns `create docker`, which writes a Dockerfile,
+// .dockerignore and compose.yaml for the project.
+func CreateDockerCmd() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "docker",
+		Short: "Create a Dockerfile, .dockerignore and compose.yaml",
+		Long: "Create a multi-stage Dockerfile, a .dockerignore and a compose.yaml with a database\n" +
+			"service matching the example config and a one-shot service running `kygo migrate up`.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := project.Root()
+			data, err := docker.FromConfig(root)
+			if err != nil {
+				return err
+			}
+			files, err := docker.Files(data)
+			if err != nil {
+				return err
+			}
+			if !force {
+				for _, f := range files {
+					if _, err := os.Stat(filepath.Join(root, f.Path)); err == nil {
+						return fmt.Errorf("%s already exists; use --force to overwrite", f.Path)
+					}
+				}
+			}
+			for _, f := range files {
+				if err := os.WriteFile(filepath.Join(root, f.Path), f.Content, 0644); err != nil {
+					return err
+				}
+				ui.Successf("Created %s", f.Path)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite existing files")
+	return cmd
+}
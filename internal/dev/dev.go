@@ -0,0 +1,219 @@
+package dev
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/swagger"
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// options are the flags of `kygo dev`.
+type options struct {
+	pkg      string
+	interval time.Duration
+	debounce time.Duration
+	grace    time.Duration
+	swagger  bool
+	mainFile string
+	args     []string
+}
+
+// DevCmd returns the `dev` command.
+func DevCmd() *cobra.Command {
+	var o options
+	cmd := &cobra.Command{
+		Use:   "dev [-- app arguments]",
+		Short: "Build and run the project, rebuilding and restarting it on changes",
+		Long: "Build the project into a temporary binary and run it. Go files, config files and\n" +
+			"translations are watched; once changes settle the app is rebuilt (for Go changes)\n" +
+			"and restarted. The running app is stopped with an interrupt and killed if it\n" +
+			"hasn't exited after --grace. When a build fails the error is shown and the\n" +
+			"previous build keeps running.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.args = args
+			return run(&o)
+		},
+	}
+	cmd.Flags().StringVar(&o.pkg, "package", ".", "package to build, relative to the project root")
+	cmd.Flags().DurationVar(&o.interval, "interval", 500*time.Millisecond, "how often to check for changes")
+	cmd.Flags().DurationVar(&o.debounce, "debounce", 300*time.Millisecond, "wait until files have been unchanged this long before rebuilding")
+	cmd.Flags().DurationVar(&o.grace, "grace", 5*time.Second, "time the app is given to shut down before it is killed")
+	cmd.Flags().BoolVar(&o.swagger, "swagger", false, "generate swagger docs before each build")
+	cmd.Flags().StringVar(&o.mainFile, "swagger-main", "main.go", "main file with the general swagger annotations")
+	return cmd
+}
+
+func run(o *options) error {
+	if _, err := exec.LookPath("go"); err != nil {
+		return fmt.Errorf("go not found in PATH: %w", err)
+	}
+	root := project.Root()
+	proj, err := project.Current()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp("", "kygo-dev-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// generated docs must not trigger another rebuild
+	w := newWatcher(root, proj.Dirs.Lang, []string{proj.Dirs.Docs, "dist", "tmp"})
+	a := &app{root: root, bin: filepath.Join(tmp, "app"+exeSuffix()), o: o, docs: proj.Dirs.Docs}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	if a.build() {
+		a.start()
+	}
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+	var pending []string
+	var last time.Time
+	for {
+		select {
+		case <-stop:
+			ui.Info("Stopping")
+			a.stop()
+			return nil
+		case <-ticker.C:
+			if changed := w.changes(); len(changed) > 0 {
+				pending = append(pending, changed...)
+				last = time.Now()
+				continue
+			}
+			if len(pending) == 0 || time.Since(last) < o.debounce {
+				continue
+			}
+			ui.Info(describe(pending))
+			rebuild := needsBuild(pending)
+			pending = nil
+			if rebuild && !a.build() {
+				continue
+			}
+			a.stop()
+			a.start()
+		}
+	}
+}
+
+// describe summarises the changed files for the restart message.
+func describe(changed []string) string {
+	if len(changed) == 1 {
+		return "Changed: " + filepath.ToSlash(changed[0])
+	}
+	return fmt.Sprintf("Changed: %s and %d more", filepath.ToSlash(changed[0]), len(changed)-1)
+}
+
+// app is the project being built and run.
+type app struct {
+	root string
+	bin  string
+	docs string
+	o    *options
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// build compiles the project into a.bin, generating swagger docs first when
+// asked. On failure the error is printed and false returned, leaving the
+// previous binary in place.
+func (a *app) build() bool {
+	if a.o.swagger {
+		if err := swagger.Generate(a.root, a.o.mainFile, a.docs); err != nil {
+			ui.Warning("swagger generation failed; building anyway")
+		}
+	}
+	ui.Info("Building...")
+	next := a.bin + ".next"
+	c := exec.Command("go", "build", "-o", next, a.o.pkg)
+	c.Dir = a.root
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	start := time.Now()
+	if err := c.Run(); err != nil {
+		ui.Errorf("Build failed:\n%s", strings.TrimRight(out.String(), "\n"))
+		return false
+	}
+	if err := os.Rename(next, a.bin); err != nil {
+		ui.Errorf("Build failed: %v", err)
+		return false
+	}
+	ui.Successf("Built in %s", time.Since(start).Round(time.Millisecond))
+	return true
+}
+
+// start runs the last successful build, if there is one.
+func (a *app) start() {
+	if _, err := os.Stat(a.bin); err != nil {
+		return
+	}
+	c := exec.Command(a.bin, a.o.args...)
+	c.Dir = a.root
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Start(); err != nil {
+		ui.Errorf("Starting the app failed: %v", err)
+		return
+	}
+	a.cmd, a.done = c, make(chan struct{})
+	go func(c *exec.Cmd, done chan struct{}) {
+		err := c.Wait()
+		if err != nil && c.ProcessState != nil && !c.ProcessState.Exited() {
+			// stopped by a signal, usually ours
+			err = nil
+		}
+		if err != nil {
+			ui.Warning(fmt.Sprintf("app exited: %v; waiting for changes", err))
+		}
+		close(done)
+	}(c, a.done)
+}
+
+// stop interrupts the app so it can shut down cleanly, and kills it after
+// the grace period.
+func (a *app) stop() {
+	if a.cmd == nil {
+		return
+	}
+	defer func() { a.cmd = nil }()
+	select {
+	case <-a.done:
+		return
+	default:
+	}
+	if runtime.GOOS == "windows" || a.cmd.Process.Signal(os.Interrupt) != nil {
+		_ = a.cmd.Process.Kill()
+	}
+	select {
+	case <-a.done:
+	case <-time.After(a.o.grace):
+		ui.Warning(fmt.Sprintf("app did not stop within %s; killing it", a.o.grace))
+		_ = a.cmd.Process.Kill()
+		<-a.done
+	}
+}
+
+func exeSuffix() string {
+	if runtime.GOOS == "windows" {
+		return ".exe"
+	}
+	return ""
+}
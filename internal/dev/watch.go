@@ -0,0 +1,125 @@
+package dev
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileState is what the watcher compares between polls.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watcher polls the project for changes to the files that affect the
+// running app. Polling works the same on every platform and filesystem,
+// including mounted volumes where change notifications are unreliable.
+type watcher struct {
+	root    string
+	lang    string   // translations directory, relative to root
+	ignored []string // directories never watched, relative to root
+	files   map[string]fileState
+}
+
+func newWatcher(root, lang string, ignored []string) *watcher {
+	w := &watcher{root: root, lang: filepath.Clean(lang), ignored: ignored}
+	w.files = w.scan()
+	return w
+}
+
+// watched reports whether the file at rel (relative to the root) is
+// watched: Go sources, config files and translations.
+func (w *watcher) watched(rel string) bool {
+	name := filepath.Base(rel)
+	switch {
+	case strings.HasSuffix(name, ".go"):
+		return !strings.HasSuffix(name, "_test.go")
+	case strings.HasPrefix(name, "config") && isConfigExt(filepath.Ext(name)):
+		return filepath.Dir(rel) == "."
+	}
+	return strings.HasPrefix(rel, w.lang+string(filepath.Separator))
+}
+
+func isConfigExt(ext string) bool {
+	switch ext {
+	case ".json", ".yaml", ".yml", ".toml":
+		return true
+	}
+	return false
+}
+
+func (w *watcher) skipDir(rel string) bool {
+	if rel == "." {
+		return false
+	}
+	name := filepath.Base(rel)
+	if strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" {
+		return true
+	}
+	for _, d := range w.ignored {
+		if rel == filepath.Clean(d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *watcher) scan() map[string]fileState {
+	files := map[string]fileState{}
+	_ = filepath.WalkDir(w.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// the file went away between listing and reading
+			return nil
+		}
+		rel, err := filepath.Rel(w.root, p)
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if w.skipDir(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !w.watched(rel) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[rel] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+	return files
+}
+
+// changes rescans the project and returns the files added, changed or
+// removed since the previous call.
+func (w *watcher) changes() []string {
+	current := w.scan()
+	var changed []string
+	for p, s := range current {
+		if old, ok := w.files[p]; !ok || old != s {
+			changed = append(changed, p)
+		}
+	}
+	for p := range w.files {
+		if _, ok := current[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	w.files = current
+	return changed
+}
+
+// needsBuild reports whether any of the changed files is Go source;
+// config and translation changes only need a restart.
+func needsBuild(changed []string) bool {
+	for _, p := range changed {
+		if strings.HasSuffix(p, ".go") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package docker
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/project"
+)
+
+//go:embed templates/*.gotmpl
+var templatesFS embed.FS
+
+var tmpl = template.Must(template.ParseFS(templatesFS, "templates/*.gotmpl"))
+
+// KygoPackage is installed in the image of the migration service.
+const KygoPackage = "github.com/go-kyugo/kygo@latest"
+
+// File is a generated file, relative to the project root.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// files maps generated files to their templates.
+var files = []struct{ path, tpl string }{
+	{"Dockerfile", "Dockerfile.gotmpl"},
+	{".dockerignore", "dockerignore.gotmpl"},
+	{"compose.yaml", "compose.yaml.gotmpl"},
+}
+
+// Data is what the Docker templates are rendered with.
+type Data struct {
+	Name        string // binary and image name
+	ConfigFile  string // config file the app reads, e.g. config.json
+	ExampleFile string // committed example config copied into the image
+	Port        int    // port the app listens on
+	Resources   bool   // whether resources/ exists and is copied
+	Migrations  string // migrations directory
+	Kygo        string // go install target for the migration image
+	DB          *Database
+}
+
+// Database describes the database service of compose.yaml.
+type Database struct {
+	Kind     string // postgres, mysql, mariadb or sqlite
+	Image    string
+	Port     int
+	Name     string
+	User     string
+	Password string
+}
+
+// InternalPort is the port the database listens on inside its container.
+func (d *Database) InternalPort() int {
+	if d.Kind == "postgres" {
+		return 5432
+	}
+	return 3306
+}
+
+// Root reports whether this is the MySQL root user, which the mysql images
+// configure separately.
+func (d *Database) Root() bool { return d.User == "root" }
+
+// FromConfig builds the template data from the project at root, reading
+// database settings from the example config as written so secret
+// references become compose variables instead of leaking into the file.
+func FromConfig(root string) (*Data, error) {
+	example := config.DiscoverExample(root)
+	cfg, err := config.ReadFile(example)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filepath.Base(example), err)
+	}
+	proj, err := project.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	abs, _ := filepath.Abs(root)
+	info, err := os.Stat(filepath.Join(root, "resources"))
+	return FromExample(filepath.Base(abs), filepath.Base(example), cfg, proj.Dirs.Migrations, err == nil && info.IsDir())
+}
+
+// FromExample builds the template data from an example config already
+// loaded; name is used when the config has no app.name and migrations is
+// the project's migrations directory.
+func FromExample(name, exampleFile string, cfg *config.Config, migrations string, resources bool) (*Data, error) {
+	if cfg.App.Name != "" {
+		name = cfg.App.Name
+	}
+	d := &Data{
+		Name:        imageName(name),
+		ConfigFile:  strings.Replace(exampleFile, "config.example", "config", 1),
+		ExampleFile: exampleFile,
+		Port:        cfg.Server.Port,
+		Resources:   resources,
+		Migrations:  cfg.Database.MigrationsPath(migrations, config.DefaultConnection),
+		Kygo:        KygoPackage,
+	}
+	if d.Port == 0 {
+		d.Port = 8080
+	}
+	if cfg.Database.Type != "" {
+		var err error
+		if d.DB, err = database(cfg.Database); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// database maps a configured connection onto a compose service.
+func database(db config.Database) (*Database, error) {
+	d := &Database{
+		Port:     db.Port,
+		Name:     composeValue("database.dbname", db.DBName),
+		User:     composeValue("database.user", db.User),
+		Password: composeValue("database.password", db.Password),
+	}
+	switch strings.ToLower(db.Type) {
+	case "postgres", "pg", "postgresql":
+		d.Kind, d.Image = "postgres", "postgres:17"
+		if d.Port == 0 {
+			d.Port = 5432
+		}
+	case "mysql":
+		d.Kind, d.Image = "mysql", "mysql:8.4"
+	case "mariadb":
+		d.Kind, d.Image = "mariadb", "mariadb:11"
+	case "sqlite", "sqlite3":
+		d.Kind = "sqlite"
+		d.Name = path.Base(filepath.ToSlash(db.DBName))
+		return d, nil
+	default:
+		return nil, fmt.Errorf("unsupported database type %q", db.Type)
+	}
+	if d.Port == 0 {
+		d.Port = 3306
+	}
+	// the mysql images only create a user that has a password
+	if !d.Root() {
+		d.Password = required("database.password", d.Password)
+	}
+	return d, nil
+}
+
+// required makes compose refuse to start without a value: an empty one
+// becomes the KYGO_ variable for key, which must then be set, e.g. in .env.
+func required(key, v string) string {
+	name := config.EnvName(key)
+	switch {
+	case v == "":
+	case strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}"): // from composeValue
+		name = v[2 : len(v)-1]
+	default:
+		return v
+	}
+	return "${" + name + ":?set " + name + ", e.g. in .env}"
+}
+
+// composeValue turns a secret reference into a compose variable: env:NAME
+// becomes ${NAME}, file: references ${KYGO_<KEY>}.
+func composeValue(key, v string) string {
+	switch {
+	case strings.HasPrefix(v, "env:"):
+		return "${" + strings.TrimPrefix(v, "env:") + "}"
+	case config.IsReference(v):
+		return "${" + config.EnvName(key) + "}"
+	}
+	// a literal $ would start a compose interpolation
+	return strings.ReplaceAll(v, "$", "$$")
+}
+
+// imageName lowercases name and replaces characters images can't contain.
+func imageName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, name)
+}
+
+// Files renders the Dockerfile, .dockerignore and compose.yaml.
+func Files(d *Data) ([]File, error) {
+	var out []File
+	for _, f := range files {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, f.tpl, d); err != nil {
+			return nil, err
+		}
+		out = append(out, File{Path: f.path, Content: buf.Bytes()})
+	}
+	return out, nil
+}
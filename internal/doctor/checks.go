@@ -0,0 +1,327 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	mgdb "github.com/golang-migrate/migrate/v4/database"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/lang"
+	"github.com/go-kyugo/kygo/internal/migrate"
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/routes"
+)
+
+// connectTimeout bounds each database check; drivers otherwise wait for the
+// operating system's TCP timeout on unreachable hosts.
+const connectTimeout = 5 * time.Second
+
+// checkGo compares the Go toolchain with the go directive of go.mod.
+func checkGo(e *env) []result {
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return []result{fail("Go", "go not found in PATH")}
+	}
+	have := strings.TrimSpace(string(out))
+	b, err := os.ReadFile(filepath.Join(e.root, "go.mod"))
+	if err != nil {
+		return []result{fail("Go", "no go.mod in "+e.root)}
+	}
+	f, err := modfile.ParseLax("go.mod", b, nil)
+	if err != nil {
+		return []result{fail("Go", err.Error())}
+	}
+	if f.Go == nil {
+		return []result{warn("Go", have+"; go.mod has no go directive")}
+	}
+	want := f.Go.Version
+	v := "v" + strings.TrimPrefix(strings.Fields(have)[0], "go")
+	if !semver.IsValid(v) || !semver.IsValid("v"+want) {
+		return []result{warn("Go", fmt.Sprintf("cannot compare %s with go %s from go.mod", have, want))}
+	}
+	if semver.Compare(v, "v"+want) < 0 {
+		return []result{fail("Go", fmt.Sprintf("go.mod requires go %s, found %s", want, have))}
+	}
+	return []result{pass("Go", fmt.Sprintf("%s (go.mod requires %s)", have, want))}
+}
+
+// checkConfig checks that the config file exists and is valid.
+func checkConfig(e *env) []result {
+	path := config.Discover("")
+	name := rel(e.root, path)
+	if _, err := os.Stat(path); err != nil {
+		detail := name + " not found"
+		if example := config.DiscoverExample(""); fileExists(example) {
+			detail += "; copy " + rel(e.root, example) + " and fill it in"
+		}
+		return []result{fail("Config", detail)}
+	}
+	issues, err := config.Validate("")
+	if err != nil {
+		return []result{fail("Config", fmt.Sprintf("%s: %v", name, err))}
+	}
+	var errs, warns []string
+	for _, i := range issues {
+		if i.Warning {
+			warns = append(warns, i.String())
+		} else {
+			errs = append(errs, i.String())
+		}
+	}
+	switch {
+	case len(errs) > 0:
+		return []result{fail("Config", fmt.Sprintf("%s has %d error(s): %s", name, len(errs), list(errs)))}
+	case len(warns) > 0:
+		return []result{warn("Config", fmt.Sprintf("%s has %d warning(s): %s", name, len(warns), list(warns)))}
+	}
+	return []result{pass("Config", name+" is valid")}
+}
+
+// checkDatabases connects to every configured database and reports the
+// migration state.
+func checkDatabases(e *env) []result {
+	if e.cfg == nil {
+		return []result{warn("Database", "skipped; the config could not be loaded")}
+	}
+	names := e.cfg.ConnectionNames()
+	if len(names) == 0 {
+		return []result{pass("Database", "no database configured")}
+	}
+	var results []result
+	for _, name := range names {
+		db, err := e.cfg.Connection(name)
+		label := "Database"
+		if name != config.DefaultConnection {
+			label += " " + name
+		}
+		if err != nil {
+			results = append(results, fail(label, err.Error()))
+			continue
+		}
+		dsn := db.URL()
+		if dsn == "" {
+			results = append(results, fail(label, fmt.Sprintf("unsupported type %q", db.Type)))
+			continue
+		}
+		// opening a SQLite database creates the file
+		if strings.HasPrefix(dsn, "sqlite") && !strings.Contains(dsn, ":memory:") && !fileExists(project.Path(db.DBName)) {
+			results = append(results, warn(label, db.DBName+" does not exist yet; run kygo migrate up"))
+			continue
+		}
+		version, dirty, err := status(dsn)
+		if err != nil {
+			results = append(results, fail(label, fmt.Sprintf("cannot connect to %s: %v", config.MaskDSN(dsn), err)))
+			continue
+		}
+		results = append(results, pass(label, "reachable at "+config.MaskDSN(dsn)))
+
+		label = strings.Replace(label, "Database", "Migrations", 1)
+		latest := latestMigration(project.Path(db.MigrationsPath(e.proj.Dirs.Migrations, name)))
+		switch {
+		case dirty:
+			results = append(results, fail(label, fmt.Sprintf("dirty at version %d; fix the database by hand, then run kygo migrate force <version>", version)))
+		case version == mgdb.NilVersion && latest > 0:
+			results = append(results, warn(label, "no migrations applied; run kygo migrate up"))
+		case version < latest:
+			results = append(results, warn(label, fmt.Sprintf("at version %d, latest is %d; run kygo migrate up", version, latest)))
+		case version == mgdb.NilVersion:
+			results = append(results, pass(label, "no migrations"))
+		default:
+			results = append(results, pass(label, fmt.Sprintf("up to date at version %d", version)))
+		}
+	}
+	return results
+}
+
+// status is migrate.Status with a timeout.
+func status(dsn string) (int, bool, error) {
+	type reply struct {
+		version int
+		dirty   bool
+		err     error
+	}
+	ch := make(chan reply, 1)
+	go func() {
+		v, d, err := migrate.Status(dsn)
+		ch <- reply{v, d, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.version, r.dirty, r.err
+	case <-time.After(connectTimeout):
+		return 0, false, fmt.Errorf("timed out after %s", connectTimeout)
+	}
+}
+
+// latestMigration returns the highest version among the migrations in dir.
+func latestMigration(dir string) int {
+	latest := 0
+	entries, _ := os.ReadDir(dir)
+	for _, ent := range entries {
+		prefix, _, ok := strings.Cut(ent.Name(), "_")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.Atoi(prefix); err == nil && v > latest {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// checkDownMigrations reports up migrations without a down migration in
+// every migrations directory of the project.
+func checkDownMigrations(e *env) []result {
+	dirs := []string{project.Path(e.proj.Dirs.Migrations)}
+	if e.cfg != nil {
+		for _, name := range e.cfg.ConnectionNames() {
+			if db, err := e.cfg.Connection(name); err == nil {
+				dirs = append(dirs, project.Path(db.MigrationsPath(e.proj.Dirs.Migrations, name)))
+			}
+		}
+	}
+	seen := map[string]bool{}
+	var missing []string
+	total := 0
+	for _, dir := range dirs {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, ent := range entries {
+			name := ent.Name()
+			if !strings.HasSuffix(name, ".up.sql") {
+				continue
+			}
+			total++
+			down := strings.TrimSuffix(name, ".up.sql") + ".down.sql"
+			if !fileExists(filepath.Join(dir, down)) {
+				missing = append(missing, rel(e.root, filepath.Join(dir, name)))
+			}
+		}
+	}
+	switch {
+	case total == 0:
+		return []result{pass("Down migrations", "no migrations")}
+	case len(missing) > 0:
+		return []result{warn("Down migrations", fmt.Sprintf("%d migration(s) cannot be rolled back: %s", len(missing), list(missing)))}
+	}
+	return []result{pass("Down migrations", fmt.Sprintf("all %d migration(s) have a down migration", total))}
+}
+
+// checkControllers reports controllers registered in the route file whose
+// package or constructor doesn't exist.
+func checkControllers(e *env) []result {
+	analysis, err := routes.Analyze()
+	if err != nil {
+		return []result{warn("Controllers", "cannot read the route file: "+err.Error())}
+	}
+	if len(analysis.Missing) == 0 {
+		return []result{pass("Controllers", "every controller registered in the route file exists")}
+	}
+	var results []result
+	for _, m := range analysis.Missing {
+		results = append(results, fail("Controllers", m))
+	}
+	return results
+}
+
+// checkSwagger compares the generated swagger docs with the newest Go file.
+func checkSwagger(e *env) []result {
+	docs := project.Path(e.proj.Dirs.Docs)
+	var generated time.Time
+	for _, name := range []string{"swagger.json", "swagger.yaml", "docs.go"} {
+		if info, err := os.Stat(filepath.Join(docs, name)); err == nil && info.ModTime().After(generated) {
+			generated = info.ModTime()
+		}
+	}
+	if generated.IsZero() {
+		return []result{pass("Swagger docs", "not generated; skipped")}
+	}
+	var newest string
+	var newestTime time.Time
+	_ = filepath.WalkDir(e.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != e.root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || p == docs) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(newestTime) {
+			newest, newestTime = p, info.ModTime()
+		}
+		return nil
+	})
+	if newestTime.After(generated) {
+		return []result{warn("Swagger docs", fmt.Sprintf("%s changed after the docs were generated; run kygo swagger generate", rel(e.root, newest)))}
+	}
+	return []result{pass("Swagger docs", "up to date")}
+}
+
+// checkLocales compares the keys of every translation file across locales.
+func checkLocales(e *env) []result {
+	dir := project.Path(e.proj.Dirs.Lang)
+	c, err := lang.Load(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []result{pass("Translations", "no "+rel(e.root, dir)+" directory; skipped")}
+	}
+	if err != nil {
+		return []result{fail("Translations", err.Error())}
+	}
+	if len(c.Locales) < 2 {
+		return []result{pass("Translations", fmt.Sprintf("%d locale(s); nothing to compare", len(c.Locales)))}
+	}
+	var results []result
+	missing := c.Missing(false)
+	for _, locale := range c.Locales {
+		if keys := missing[locale]; len(keys) > 0 {
+			results = append(results, warn("Translations", fmt.Sprintf("%s is missing %d key(s): %s; see kygo lang missing", locale, len(keys), list(keys))))
+		}
+	}
+	if len(results) == 0 {
+		results = append(results, pass("Translations", fmt.Sprintf("%d locales have the same keys", len(c.Locales))))
+	}
+	return results
+}
+
+// list joins items for a one-line detail, eliding the tail of long lists.
+func list(items []string) string {
+	const limit = 5
+	if len(items) <= limit {
+		return strings.Join(items, ", ")
+	}
+	return strings.Join(items[:limit], ", ") + fmt.Sprintf(" and %d more", len(items)-limit)
+}
+
+func fileExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+func rel(root, p string) string {
+	if r, err := filepath.Rel(root, p); err == nil && !strings.HasPrefix(r, "..") {
+		return filepath.ToSlash(r)
+	}
+	return p
+}
@@ -0,0 +1,88 @@
+package doctor
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// Check outcomes.
+const (
+	Pass = "PASS"
+	Warn = "WARN"
+	Fail = "FAIL"
+)
+
+// result is the outcome of one check.
+type result struct {
+	Status string
+	Name   string
+	Detail string
+}
+
+func pass(name, detail string) result { return result{Pass, name, detail} }
+func warn(name, detail string) result { return result{Warn, name, detail} }
+func fail(name, detail string) result { return result{Fail, name, detail} }
+
+// env is what the checks share: the project and its config, loaded once.
+type env struct {
+	root   string
+	proj   *project.Project
+	cfg    *config.Config // nil when the config is missing or invalid
+	cfgErr error
+}
+
+// checks run in order; each may report several results.
+var checks = []func(e *env) []result{
+	checkGo,
+	checkConfig,
+	checkDatabases,
+	checkDownMigrations,
+	checkControllers,
+	checkSwagger,
+	checkLocales,
+}
+
+// DoctorCmd returns the `doctor` command.
+func DoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the project and the development setup for common problems",
+		Long: "Check the Go toolchain against go.mod, the config file, database connections and\n" +
+			"migration state, down migrations, controllers registered in the route file, swagger\n" +
+			"docs and translation files. Each check prints PASS, WARN or FAIL; the command exits\n" +
+			"non-zero when any check fails.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			proj, err := project.Current()
+			if err != nil {
+				return err
+			}
+			e := &env{root: project.Root(), proj: proj}
+			e.cfg, e.cfgErr = config.Load("")
+
+			counts := map[string]int{}
+			for _, check := range checks {
+				for _, r := range check(e) {
+					counts[r.Status]++
+					msg := r.Name
+					if r.Detail != "" {
+						msg += ": " + r.Detail
+					}
+					ui.Check(r.Status, msg)
+				}
+			}
+			ui.Println()
+			summary := fmt.Sprintf("%d passed, %d warning(s), %d failed", counts[Pass], counts[Warn], counts[Fail])
+			if counts[Fail] > 0 {
+				return fmt.Errorf("%s", summary)
+			}
+			ui.Success(summary)
+			return nil
+		},
+	}
+}
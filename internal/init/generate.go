@@ -0,0 +1,155 @@
+package initpkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/docker"
+	"github.com/go-kyugo/kygo/internal/project"
+)
+
+// projectFiles are rendered project files keyed by slash-separated path
+// relative to the project root, in the order they were generated.
+type projectFiles struct {
+	paths   []string
+	content map[string][]byte
+}
+
+func (p *projectFiles) add(name string, content []byte) {
+	if _, ok := p.content[name]; !ok {
+		p.paths = append(p.paths, name)
+	}
+	p.content[name] = content
+}
+
+// generate renders the project described by opts in memory: every layer of
+// the preset, base first, so files of a preset replace those of the preset
+// it extends, then kygo.json unless the template ships one, then the Docker
+// files when the feature is selected.
+func generate(opts *options, configFormat string) (*projectFiles, error) {
+	files := &projectFiles{content: map[string][]byte{}}
+	for _, layer := range opts.preset.Layers {
+		if err := renderLayer(files, layer, configFormat, opts); err != nil {
+			return nil, err
+		}
+	}
+	proj := project.Default()
+	if content, ok := files.content[project.FileName]; ok {
+		if err := json.Unmarshal(content, proj); err != nil {
+			return nil, fmt.Errorf("%s: %w", project.FileName, err)
+		}
+	} else {
+		proj.Module = opts.Module
+		if opts.Database != nil {
+			proj.Dialect = opts.Database.Type
+		}
+		content, err := proj.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		files.add(project.FileName, content)
+	}
+	if !opts.Features["docker"] {
+		return files, nil
+	}
+	example := "config.example" + config.Extension(configFormat)
+	content, ok := files.content[example]
+	if !ok {
+		return nil, fmt.Errorf("the docker feature needs %s in the template", example)
+	}
+	cfg, err := config.Parse(example, content)
+	if err != nil {
+		return nil, err
+	}
+	resources := false
+	for _, p := range append(files.paths, opts.dirs()...) {
+		if strings.HasPrefix(p, "resources/") {
+			resources = true
+		}
+	}
+	data, err := docker.FromExample(opts.Name, example, cfg, proj.Dirs.Migrations, resources)
+	if err != nil {
+		return nil, err
+	}
+	dockerFiles, err := docker.Files(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range dockerFiles {
+		files.add(f.Path, f.Content)
+	}
+	return files, nil
+}
+
+// renderLayer walks a template tree and adds every file, executed as a
+// template with opts, to files.
+func renderLayer(files *projectFiles, layer fs.FS, configFormat string, opts *options) error {
+	return fs.WalkDir(layer, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := name
+		if rel == "." || rel == manifestFile {
+			return nil
+		}
+		if opts.skipped(rel) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		// translations are authored for templateLocale
+		if prefix := "resources/lang/" + templateLocale; rel == prefix || strings.HasPrefix(rel, prefix+"/") {
+			rel = "resources/lang/" + opts.Locale + strings.TrimPrefix(rel, prefix)
+		}
+		if d.IsDir() {
+			return nil
+		}
+		content, err := fs.ReadFile(layer, name)
+		if err != nil {
+			return err
+		}
+		// process as template
+		if content, err = render(rel, string(content), opts); err != nil {
+			return err
+		}
+		// strip .gotmpl suffix if present
+		dir, outName := path.Split(rel)
+		outName = strings.TrimSuffix(outName, ".gotmpl")
+		// the example config is authored as JSON; translate it when
+		// another format was requested
+		if outName == "config.example.json" && configFormat != config.FormatJSON {
+			if content, err = config.Convert(content, config.FormatJSON, configFormat); err != nil {
+				return err
+			}
+			outName = "config.example" + config.Extension(configFormat)
+		}
+		if path.Ext(outName) == ".go" {
+			if content, err = format.Source(content); err != nil {
+				return fmt.Errorf("formatting %s: %w", rel, err)
+			}
+		}
+		files.add(dir+outName, content)
+		return nil
+	})
+}
+
+// render executes text as a project template.
+func render(name, text string, opts *options) ([]byte, error) {
+	tpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1,146 +1,139 @@
 package initpkg
 
 import (
-	"bytes"
-	"embed"
 	"fmt"
-	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"strings"
-	"text/template"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
+	"github.com/go-kyugo/kygo/internal/config"
 	"github.com/go-kyugo/kygo/internal/ui"
 )
 
-//go:embed templates/project/**
-var projectFS embed.FS
-
 // MakeInitCmd returns the `init` command with `project` subcommand.
 func MakeInitCmd() *cobra.Command {
 
-	// remove any placeholder KEEP files copied from templates
-	// (these are used only so the embed includes empty dirs)
-	removeKEEPs := func(root string) error {
-		return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+	projectCmd := &cobra.Command{
+		Use:   "init <name|.>",
+		Short: "Create a new project skeleton",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outDir := args[0]
+			// the project is named after its directory, so `init .` uses
+			// the current one
+			abs, err := filepath.Abs(outDir)
 			if err != nil {
 				return err
 			}
-			if d.IsDir() {
-				return nil
+			name := filepath.Base(abs)
+			merge, _ := cmd.Flags().GetBool("merge")
+			force, _ := cmd.Flags().GetBool("force")
+			w, err := newWriter(outDir, merge, force)
+			if err != nil {
+				return err
 			}
-			if d.Name() == "KEEP" {
-				if err := os.Remove(p); err != nil {
-					return fmt.Errorf("removing KEEP %s: %w", p, err)
-				}
+			configFormat, _ := cmd.Flags().GetString("config-format")
+			if configFormat == "yml" {
+				configFormat = config.FormatYAML
 			}
-			return nil
-		})
-	}
-
-	projectCmd := &cobra.Command{
-		Use:   "init <name>",
-		Short: "Create a new project skeleton",
-		Args:  cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			name := args[0]
-			outDir := name
+			switch configFormat {
+			case config.FormatJSON, config.FormatYAML, config.FormatTOML:
+			default:
+				return fmt.Errorf("unsupported --config-format %q (use json, yaml or toml)", configFormat)
+			}
+			opts, err := resolveOptions(cmd, name)
+			if err != nil {
+				return err
+			}
+			defer opts.cleanup()
+			opts.ConfigFile = "config" + config.Extension(configFormat)
 			if err := os.MkdirAll(outDir, 0755); err != nil {
 				return err
 			}
 
-			// create standard directories
-			dirs := []string{
-				"http/controller",
-				"http/middleware",
-				"http/validation",
-				"http/route",
-				"dto",
-				"service",
-				"resources/lang/en-US",
-				"database/model",
-				"database/repository",
-				"database/migrations",
-				"database/seed",
+			files, err := generate(opts, configFormat)
+			if err != nil {
+				return err
 			}
-			for _, d := range dirs {
-				p := filepath.Join(outDir, d)
-				if err := os.MkdirAll(p, 0755); err != nil {
+			for _, p := range files.paths {
+				if err := w.write(filepath.Join(outDir, filepath.FromSlash(p)), files.content[p]); err != nil {
 					return err
 				}
-				// add a .gitkeep to ensure directory is tracked
-				gitkeep := filepath.Join(p, ".gitkeep")
-				if _, err := os.Stat(gitkeep); os.IsNotExist(err) {
-					if err := os.WriteFile(gitkeep, []byte(""), 0644); err != nil {
-						return err
-					}
-				}
 			}
 
-			// walk embedded templates/project recursively and copy files
-			data := struct{ Name string }{Name: name}
-			walkRoot := "templates/project"
-			if err := fs.WalkDir(projectFS, walkRoot, func(path string, d fs.DirEntry, err error) error {
-				if err != nil {
-					return err
-				}
-				// compute relative path under templates/project
-				rel := strings.TrimPrefix(path, walkRoot)
-				rel = strings.TrimPrefix(rel, string(filepath.Separator))
-				if rel == "" {
-					return nil
-				}
-				targetPath := filepath.Join(outDir, rel)
-				if d.IsDir() {
-					return os.MkdirAll(targetPath, 0755)
-				}
-				// skip placeholder files used only for embedding
-				if d.Name() == "KEEP" {
-					return nil
-				}
-				// read file content from embedded FS
-				content, err := projectFS.ReadFile(path)
-				if err != nil {
+			if err := writeManifest(outDir, opts, files); err != nil {
+				return err
+			}
+			if err := writeDotEnv(w, outDir, opts.Database); err != nil {
+				return err
+			}
+
+			// create standard directories
+			for _, d := range opts.dirs() {
+				p := filepath.Join(outDir, d)
+				if err := os.MkdirAll(p, 0755); err != nil {
 					return err
 				}
-				// process as template
-				tpl, err := template.New(rel).Parse(string(content))
+				// add a .gitkeep to ensure an empty directory is tracked
+				entries, err := os.ReadDir(p)
 				if err != nil {
 					return err
 				}
-				var buf bytes.Buffer
-				if err := tpl.Execute(&buf, data); err != nil {
-					return err
-				}
-				// strip .gotmpl suffix if present
-				outName := filepath.Base(rel)
-				if filepath.Ext(outName) == ".gotmpl" {
-					outName = outName[:len(outName)-len(".gotmpl")]
-				}
-				outDirPath := filepath.Join(outDir, filepath.Dir(rel))
-				if err := os.MkdirAll(outDirPath, 0755); err != nil {
-					return err
-				}
-				outPath := filepath.Join(outDirPath, outName)
-				if err := os.WriteFile(outPath, buf.Bytes(), 0644); err != nil {
-					return err
+				if len(entries) == 0 {
+					gitkeep := filepath.Join(p, ".gitkeep")
+					if err := w.write(gitkeep, []byte("")); err != nil {
+						return err
+					}
 				}
-
-				return nil
-			}); err != nil {
-				return err
 			}
 
-			// remove placeholder KEEP files from the generated project
-			if err := removeKEEPs(outDir); err != nil {
+			if err := runHooks(opts.preset.Manifest.Hooks, outDir, opts); err != nil {
 				return err
 			}
+			// resolve dependencies so the project builds right away
+			if skipTidy, _ := cmd.Flags().GetBool("skip-tidy"); !skipTidy {
+				if _, err := exec.LookPath("go"); err != nil {
+					ui.Warning("go not found in PATH; run `go mod tidy` in " + outDir)
+				} else {
+					c := exec.Command("go", "mod", "tidy")
+					c.Dir = outDir
+					c.Stdout = os.Stdout
+					c.Stderr = os.Stderr
+					if err := c.Run(); err != nil {
+						ui.Warning("go mod tidy failed; run it in " + outDir + " once the dependencies are reachable")
+					}
+				}
+			}
+			for _, c := range w.Conflicts {
+				ui.Warning(c + " already exists and differs from the template; kept your version")
+			}
+			if len(w.Conflicts) > 0 {
+				ui.Info("Re-run with --force to overwrite these files")
+			}
 			ui.Successf("Created project in %s", outDir)
 			return nil
 		},
 	}
 
+	projectCmd.Flags().String("config-format", config.FormatJSON, "format of the generated example config (json, yaml or toml)")
+	projectCmd.Flags().Bool("merge", false, "add missing files to a non-empty directory and report conflicts")
+	projectCmd.Flags().Bool("force", false, "overwrite existing files")
+	projectCmd.Flags().Bool("skip-tidy", false, "do not run go mod tidy in the generated project")
+	addWizardFlags(projectCmd)
+
 	return projectCmd
 }
+
+// writeDotEnv stores the database password in the project's .env, which
+// the generated .gitignore excludes, as the variable the example config
+// references; the password never reaches a tracked file or .kygo.
+func writeDotEnv(w *writer, outDir string, db *database) error {
+	if db == nil || db.Password == "" {
+		return nil
+	}
+	line := config.EnvName("database.password") + "=" + strconv.Quote(db.Password) + "\n"
+	return w.writeFile(filepath.Join(outDir, ".env"), []byte(line), 0600)
+}
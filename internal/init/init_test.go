@@ -0,0 +1,230 @@
+package initpkg
+
+import (
+	"bytes"
+	"flag"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// testModule differs from the project name so imports built from the name
+// instead of --module show up.
+const testModule = "example.com/acme/shop"
+
+// TestPresets generates every preset, with its default features and with
+// all of them, and compares the files with testdata/golden/<case>.txt. Run
+// `go test ./internal/init -run TestPresets -update` after changing a
+// template and review the diff.
+func TestPresets(t *testing.T) {
+	for _, preset := range presetNames() {
+		for _, all := range []bool{false, true} {
+			name := preset
+			args := []string{"--preset", preset}
+			if all {
+				name += "-all"
+				args = append(args, "--features", strings.Join(featureNames(), ","), "--db-type", "sqlite")
+			}
+			t.Run(name, func(t *testing.T) {
+				dir := filepath.Join(t.TempDir(), "app")
+				cmd := MakeInitCmd()
+				cmd.SetArgs(append([]string{dir, "--no-interaction", "--skip-tidy", "--module", testModule}, args...))
+				if err := cmd.Execute(); err != nil {
+					t.Fatalf("init: %v", err)
+				}
+				checkImports(t, dir)
+				compareGolden(t, dir, filepath.Join("testdata", "golden", name+".txt"))
+				if testing.Short() {
+					return
+				}
+				buildProject(t, dir)
+			})
+		}
+	}
+}
+
+// TestPasswordNotTracked checks a password given to init only reaches the
+// git-ignored .env, not the example config, compose.yaml or .kygo.
+func TestPasswordNotTracked(t *testing.T) {
+	const password = "S3cr3t!pw"
+	dir := filepath.Join(t.TempDir(), "app")
+	cmd := MakeInitCmd()
+	cmd.SetArgs([]string{dir, "--no-interaction", "--skip-tidy", "--preset", "full", "--db-type", "mysql", "--db-password", password})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() == ".env" {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err == nil && bytes.Contains(b, []byte(password)) {
+			t.Errorf("%s contains the password", p)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := os.ReadFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "KYGO_DATABASE_PASSWORD=\"" + password + "\"\n"; string(env) != want {
+		t.Errorf(".env = %q, want %q", env, want)
+	}
+	ignore, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(ignore), "\n.env\n") {
+		t.Errorf(".gitignore does not list .env:\n%s", ignore)
+	}
+}
+
+// checkImports parses every generated Go file and fails the test for an
+// import of the project that is not under --module or names a package the
+// project doesn't have; it needs no dependencies.
+func checkImports(t *testing.T, dir string) {
+	t.Helper()
+	packages := map[string]bool{} // directories with Go files, slash-separated
+	imports := map[string][]string{}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".go") {
+			return err
+		}
+		f, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		rel = filepath.ToSlash(rel)
+		packages[path.Dir(rel)] = true
+		for _, spec := range f.Imports {
+			ip, _ := strconv.Unquote(spec.Path.Value)
+			imports[ip] = append(imports[ip], rel)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ip, files := range imports {
+		if pkg, ok := strings.CutPrefix(ip, testModule+"/"); ok {
+			if !packages[pkg] {
+				t.Errorf("%s import %s, which is not a package of the project", strings.Join(files, ", "), ip)
+			}
+			continue
+		}
+		// a project package imported under the project name
+		first, pkg, _ := strings.Cut(ip, "/")
+		if (first == filepath.Base(dir) || first == path.Base(testModule)) && packages[pkg] {
+			t.Errorf("%s import %s instead of %s/%s", strings.Join(files, ", "), ip, testModule, pkg)
+		}
+	}
+}
+
+// compareGolden checks the files under dir, except .kygo which repeats
+// them, against a golden file holding each as "-- path --" and its content.
+func compareGolden(t *testing.T, dir, golden string) {
+	t.Helper()
+	var paths []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if rel != ManifestName {
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths)
+	var got bytes.Buffer
+	for _, p := range paths {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.WriteString("-- " + p + " --\n")
+		got.Write(b)
+		if len(b) > 0 && b[len(b)-1] != '\n' {
+			got.WriteString("\n")
+		}
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v; run the test with -update to create it", err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("generated files differ from %s; run the test with -update and review the diff\n%s", golden, firstDiff(string(want), got.String()))
+	}
+}
+
+// firstDiff describes the first line where want and got differ.
+func firstDiff(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			return "line " + strconv.Itoa(i+1) + ":\n want " + strconv.Quote(wl) + "\n  got " + strconv.Quote(gl)
+		}
+	}
+	return ""
+}
+
+// buildProject compiles the generated project when its dependencies can be
+// downloaded, an extra check on top of the golden files.
+func buildProject(t *testing.T, dir string) {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Log("go not found in PATH; not building")
+		return
+	}
+	if out, err := goCmd(dir, "mod", "download"); err != nil {
+		t.Logf("dependencies unavailable, not building: %s", out)
+		return
+	}
+	for _, args := range [][]string{{"mod", "tidy"}, {"build", "./..."}, {"vet", "./..."}} {
+		if out, err := goCmd(dir, args...); err != nil {
+			t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+func goCmd(dir string, args ...string) ([]byte, error) {
+	c := exec.Command("go", args...)
+	c.Dir = dir
+	c.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	return c.CombinedOutput()
+}
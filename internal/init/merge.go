@@ -0,0 +1,170 @@
+package initpkg
+
+import (
+	"strings"
+)
+
+// conflict is a region both the user and the template changed differently.
+type conflict struct {
+	Base, Ours, Theirs []string
+}
+
+// splitLines splits s into lines, each keeping its line ending.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// matchLines returns, for every line of a, the index of the line of b it is
+// paired with in a longest common subsequence, or -1.
+func matchLines(a, b []string) []int {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	match := make([]int, len(a))
+	i, j := 0, 0
+	for i < len(a) {
+		switch {
+		case j < len(b) && a[i] == b[j]:
+			match[i] = j
+			i++
+			j++
+		case j < len(b) && lcs[i][j+1] >= lcs[i+1][j]:
+			j++
+		default:
+			match[i] = -1
+			i++
+		}
+	}
+	return match
+}
+
+// merge3 merges the changes made to base in ours and in theirs. Regions
+// changed on only one side take that side; regions changed identically take
+// either. Regions changed differently are returned as conflicts and written
+// by emit, which receives the merged output so far.
+func merge3(base, ours, theirs string, emit func(out *strings.Builder, c conflict)) (string, []conflict) {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	mo, mt := matchLines(b, o), matchLines(b, t)
+
+	var out strings.Builder
+	var conflicts []conflict
+	i, oi, ti := 0, 0, 0
+	for {
+		// lines unchanged on both sides
+		if i < len(b) && mo[i] == oi && mt[i] == ti {
+			out.WriteString(b[i])
+			i, oi, ti = i+1, oi+1, ti+1
+			continue
+		}
+		// the next base line kept by both sides ends the changed region
+		k := i
+		for k < len(b) && (mo[k] < 0 || mt[k] < 0) {
+			k++
+		}
+		oe, te := len(o), len(t)
+		if k < len(b) {
+			oe, te = mo[k], mt[k]
+		}
+		cb, co, ct := b[i:k], o[oi:oe], t[ti:te]
+		switch {
+		case equalLines(co, cb):
+			writeLines(&out, ct)
+		case equalLines(ct, cb), equalLines(co, ct):
+			writeLines(&out, co)
+		default:
+			c := conflict{Base: cb, Ours: co, Theirs: ct}
+			conflicts = append(conflicts, c)
+			emit(&out, c)
+		}
+		if k == len(b) {
+			return out.String(), conflicts
+		}
+		i, oi, ti = k, oe, te
+	}
+}
+
+// conflictMarkers writes a conflict with git-style markers.
+func conflictMarkers(out *strings.Builder, c conflict) {
+	out.WriteString("<<<<<<< yours\n")
+	writeTerminated(out, c.Ours)
+	out.WriteString("||||||| previous template\n")
+	writeTerminated(out, c.Base)
+	out.WriteString("=======\n")
+	writeTerminated(out, c.Theirs)
+	out.WriteString(">>>>>>> new template\n")
+}
+
+// keepOurs resolves a conflict in favour of the user's lines.
+func keepOurs(out *strings.Builder, c conflict) {
+	writeLines(out, c.Ours)
+}
+
+// rejects formats conflicts as the hunks of the template change that could
+// not be applied, for a .rej file.
+func rejects(path string, conflicts []conflict) string {
+	var out strings.Builder
+	out.WriteString("--- " + path + " (previous template)\n")
+	out.WriteString("+++ " + path + " (new template)\n")
+	for _, c := range conflicts {
+		out.WriteString("@@ @@\n")
+		for _, l := range c.Base {
+			out.WriteString("-" + withNewline(l))
+		}
+		for _, l := range c.Theirs {
+			out.WriteString("+" + withNewline(l))
+		}
+	}
+	return out.String()
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
+
+// writeTerminated is writeLines for text followed by a conflict marker,
+// which must start on its own line.
+func writeTerminated(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(withNewline(l))
+	}
+}
+
+// withNewline terminates a file's last line.
+func withNewline(l string) string {
+	if strings.HasSuffix(l, "\n") {
+		return l
+	}
+	return l + "\n"
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
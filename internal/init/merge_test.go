@@ -0,0 +1,105 @@
+package initpkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	const base = "a\nb\nc\nd\ne\n"
+	tests := []struct {
+		name         string
+		ours, theirs string
+		want         string
+		conflicts    int
+	}{
+		{
+			name: "unchanged",
+			ours: base, theirs: base,
+			want: base,
+		},
+		{
+			name: "user change only",
+			ours: "a\nB\nc\nd\ne\n", theirs: base,
+			want: "a\nB\nc\nd\ne\n",
+		},
+		{
+			name: "template change only",
+			ours: base, theirs: "a\nb\nc\nD\ne\nf\n",
+			want: "a\nb\nc\nD\ne\nf\n",
+		},
+		{
+			name:   "clean merge of separate changes",
+			ours:   "user\na\nb\nc\nd\ne\n",
+			theirs: "a\nb\nC\nd\ne\nf\n",
+			want:   "user\na\nb\nC\nd\ne\nf\n",
+		},
+		{
+			name: "same change on both sides",
+			ours: "a\nB\nc\nd\ne\n", theirs: "a\nB\nc\nd\ne\n",
+			want: "a\nB\nc\nd\ne\n",
+		},
+		{
+			name: "conflict",
+			ours: "a\nyours\nc\nd\ne\n", theirs: "a\ntheirs\nc\nd\ne\n",
+			want: "a\n" +
+				"<<<<<<< yours\nyours\n||||||| previous template\nb\n=======\ntheirs\n>>>>>>> new template\n" +
+				"c\nd\ne\n",
+			conflicts: 1,
+		},
+		{
+			// with no unchanged line between them the edits form one region
+			name: "adjacent edits",
+			ours: "a\nB\nc\nd\ne\n", theirs: "a\nb\nC\nd\ne\n",
+			want: "a\n" +
+				"<<<<<<< yours\nB\nc\n||||||| previous template\nb\nc\n=======\nb\nC\n>>>>>>> new template\n" +
+				"d\ne\n",
+			conflicts: 1,
+		},
+		{
+			name: "edits one line apart",
+			ours: "a\nB\nc\nd\ne\n", theirs: "a\nb\nc\nD\ne\n",
+			want: "a\nB\nc\nD\ne\n",
+		},
+		{
+			name: "deleted by the user, edited by the template",
+			ours: "a\nc\nd\ne\n", theirs: "a\nB\nc\nd\ne\n",
+			want: "a\n" +
+				"<<<<<<< yours\n||||||| previous template\nb\n=======\nB\n>>>>>>> new template\n" +
+				"c\nd\ne\n",
+			conflicts: 1,
+		},
+		{
+			name: "conflict on a last line without newline",
+			ours: "a\nb\nc\nd\nyours", theirs: "a\nb\nc\nd\ntheirs",
+			want: "a\nb\nc\nd\n" +
+				"<<<<<<< yours\nyours\n||||||| previous template\ne\n=======\ntheirs\n>>>>>>> new template\n",
+			conflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		got, conflicts := merge3(base, tt.ours, tt.theirs, conflictMarkers)
+		if got != tt.want {
+			t.Errorf("%s:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+		if len(conflicts) != tt.conflicts {
+			t.Errorf("%s: %d conflict(s), want %d", tt.name, len(conflicts), tt.conflicts)
+		}
+	}
+}
+
+func TestMerge3Reject(t *testing.T) {
+	base, ours, theirs := "a\nb\nc\n", "a\nyours\nc\n", "a\ntheirs\nc\n"
+	got, conflicts := merge3(base, ours, theirs, keepOurs)
+	if got != ours {
+		t.Errorf("keepOurs: %q, want %q", got, ours)
+	}
+	want := []conflict{{Base: []string{"b\n"}, Ours: []string{"yours\n"}, Theirs: []string{"theirs\n"}}}
+	if !reflect.DeepEqual(conflicts, want) {
+		t.Fatalf("conflicts = %q, want %q", conflicts, want)
+	}
+	rej := "--- main.go (previous template)\n+++ main.go (new template)\n@@ @@\n-b\n+theirs\n"
+	if got := rejects("main.go", conflicts); got != rej {
+		t.Errorf("rejects:\n%s\nwant:\n%s", got, rej)
+	}
+}
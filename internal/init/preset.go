@@ -0,0 +1,131 @@
+package initpkg
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+//go:embed all:templates/presets
+var presetsFS embed.FS
+
+// presetsRoot is the directory of presetsFS holding one directory per preset.
+const presetsRoot = "templates/presets"
+
+// defaultPreset is generated when --preset isn't given.
+const defaultPreset = "api"
+
+// manifestFile describes a preset; it sits at the root of the preset's
+// template directory and is not copied into the project.
+const manifestFile = "template.json"
+
+// manifest is the content of a preset's template.json.
+type manifest struct {
+	Description string     `json:"description"`
+	Extends     string     `json:"extends"`  // preset whose files this one builds on
+	Dirs        []string   `json:"dirs"`     // directories always created
+	Features    []string   `json:"features"` // features enabled by default
+	Variables   []variable `json:"variables"`
+	Hooks       []string   `json:"post_generate"` // commands run in the new project
+}
+
+// variable is a template-specific value, available to templates as
+// {{ .Vars.<name> }}.
+type variable struct {
+	Name    string   `json:"name"`
+	Prompt  string   `json:"prompt"` // question asked interactively; empty means never asked
+	Default string   `json:"default"`
+	Options []string `json:"options"` // allowed values, if restricted
+}
+
+// preset is a resolved preset: its own manifest merged with the ones it
+// extends, and the template layers to render, base first.
+type preset struct {
+	Name     string
+	Manifest manifest
+	Layers   []fs.FS
+}
+
+// presetNames lists the embedded presets.
+func presetNames() []string {
+	entries, err := fs.ReadDir(presetsFS, presetsRoot)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// embeddedPreset returns the template directory of an embedded preset.
+func embeddedPreset(name string) (fs.FS, error) {
+	if _, err := fs.Stat(presetsFS, presetsRoot+"/"+name+"/"+manifestFile); err != nil {
+		return nil, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(presetNames(), ", "))
+	}
+	return fs.Sub(presetsFS, presetsRoot+"/"+name)
+}
+
+// loadPreset resolves the embedded preset name.
+func loadPreset(name string) (*preset, error) {
+	layer, err := embeddedPreset(name)
+	if err != nil {
+		return nil, err
+	}
+	return newPreset(name, layer)
+}
+
+// newPreset resolves the template directory layer and the chain of embedded
+// presets it extends. Files of a preset replace those at the same path in
+// its parent.
+func newPreset(name string, layer fs.FS) (*preset, error) {
+	p := &preset{Name: name}
+	seen := map[string]bool{}
+	var featuresSet bool
+	for current := name; ; {
+		seen[current] = true
+		// a template without a manifest is copied as is
+		var m manifest
+		b, err := fs.ReadFile(layer, manifestFile)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("template %s: %w", current, err)
+		default:
+			if err := json.Unmarshal(b, &m); err != nil {
+				return nil, fmt.Errorf("template %s: %s: %w", current, manifestFile, err)
+			}
+		}
+		if current == name {
+			p.Manifest.Description = m.Description
+		}
+		// the most specific preset that lists features wins
+		if !featuresSet && m.Features != nil {
+			p.Manifest.Features = m.Features
+			featuresSet = true
+		}
+		p.Manifest.Dirs = append(m.Dirs, p.Manifest.Dirs...)
+		p.Manifest.Variables = append(m.Variables, p.Manifest.Variables...)
+		p.Manifest.Hooks = append(m.Hooks, p.Manifest.Hooks...)
+		p.Layers = append([]fs.FS{layer}, p.Layers...)
+
+		if m.Extends == "" {
+			return p, nil
+		}
+		if seen[m.Extends] {
+			return nil, fmt.Errorf("preset %q extends itself", m.Extends)
+		}
+		current = m.Extends
+		if layer, err = embeddedPreset(current); err != nil {
+			return nil, err
+		}
+	}
+}
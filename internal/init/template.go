@@ -0,0 +1,97 @@
+package initpkg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// loadTemplate resolves an external template: a local directory, or a git
+// repository given as git+<url>[#ref] (e.g. git+file:///srv/skeleton.git#v2).
+// The returned cleanup removes any temporary checkout.
+func loadTemplate(src string) (*preset, func(), error) {
+	dir, cleanup, err := fetchTemplate(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := newPreset(src, os.DirFS(dir))
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return p, cleanup, nil
+}
+
+// fetchTemplate returns the directory holding the template src, cloning git
+// sources into a temporary directory.
+func fetchTemplate(src string) (string, func(), error) {
+	if !strings.HasPrefix(src, "git+") {
+		info, err := os.Stat(src)
+		if err != nil {
+			return "", nil, fmt.Errorf("template: %w", err)
+		}
+		if !info.IsDir() {
+			return "", nil, fmt.Errorf("template %s is not a directory", src)
+		}
+		return src, func() {}, nil
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", nil, fmt.Errorf("git is required for %s", src)
+	}
+	url, ref, _ := strings.Cut(strings.TrimPrefix(src, "git+"), "#")
+	dir, err := os.MkdirTemp("", "kygo-template-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	args := []string{"-c", "advice.detachedHead=false", "clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	c := exec.Command("git", append(args, url, dir)...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("cloning %s: %w", url, err)
+	}
+	// the checkout's metadata isn't part of the template
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return dir, cleanup, nil
+}
+
+// runHooks runs the template's post-generation commands in the project
+// directory. Commands are templates themselves, executed with opts.
+func runHooks(hooks []string, dir string, opts *options) error {
+	for _, h := range hooks {
+		out, err := render(h, h, opts)
+		if err != nil {
+			return fmt.Errorf("hook %q: %w", h, err)
+		}
+		cmdline := string(out)
+		ui.Info("Running " + cmdline)
+		var c *exec.Cmd
+		if runtime.GOOS == "windows" {
+			c = exec.Command("cmd", "/C", cmdline)
+		} else {
+			c = exec.Command("sh", "-c", cmdline)
+		}
+		c.Dir = dir
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("hook %q failed: %w", cmdline, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package initpkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// TemplateVersion identifies the revision of the embedded templates. Bump it
+// whenever a preset changes so upgrade-project can tell projects apart.
+const TemplateVersion = "3"
+
+// ManifestName is the file init writes at the project root to remember how
+// the project was generated.
+const ManifestName = ".kygo"
+
+// projectManifest is the content of .kygo: the template version, the answers
+// given to init and the pristine content of every generated file, which is
+// the common ancestor when merging newer templates into the project.
+type projectManifest struct {
+	Version string            `json:"version"`
+	Options *options          `json:"options"`
+	Files   map[string]string `json:"files"`
+}
+
+func writeManifest(root string, opts *options, files *projectFiles) error {
+	m := projectManifest{Version: TemplateVersion, Options: opts, Files: map[string]string{}}
+	for _, p := range files.paths {
+		m.Files[p] = string(files.content[p])
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, ManifestName), append(b, '\n'), 0644)
+}
+
+func readManifest(root string) (*projectManifest, error) {
+	b, err := os.ReadFile(filepath.Join(root, ManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no %s manifest in %s; upgrade-project only works for projects created by kygo init", ManifestName, root)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m projectManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestName, err)
+	}
+	if m.Options == nil {
+		return nil, fmt.Errorf("%s: missing options", ManifestName)
+	}
+	return &m, nil
+}
+
+// UpgradeCmd returns the `upgrade-project` command.
+func UpgradeCmd() *cobra.Command {
+	var dryRun, reject bool
+	cmd := &cobra.Command{
+		Use:   "upgrade-project",
+		Short: "Bring a project generated by kygo init up to date with the current templates",
+		Long: "Render the project's templates again with the answers recorded in .kygo and merge\n" +
+			"the changes since the project was generated into your files. Changes to files you\n" +
+			"have not touched are applied as is; edited files get a three-way merge. Conflicting\n" +
+			"regions are written with conflict markers, or with --reject your version is kept and\n" +
+			"the template change is written to <file>.rej.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := project.Root()
+			m, err := readManifest(root)
+			if err != nil {
+				return err
+			}
+			opts := m.Options
+			opts.cleanup = func() {}
+			if opts.Template != "" {
+				p, cleanup, err := loadTemplate(opts.Template)
+				if err != nil {
+					return err
+				}
+				opts.preset, opts.cleanup = p, cleanup
+			} else {
+				if opts.preset, err = loadPreset(opts.Preset); err != nil {
+					return err
+				}
+			}
+			defer opts.cleanup()
+			// variables added to the template since the project was
+			// generated take their defaults
+			for _, v := range opts.preset.Manifest.Variables {
+				if _, ok := opts.Vars[v.Name]; !ok {
+					if opts.Vars == nil {
+						opts.Vars = map[string]string{}
+					}
+					opts.Vars[v.Name] = v.Default
+				}
+			}
+			configFormat, err := config.FormatOf(opts.ConfigFile)
+			if err != nil {
+				return err
+			}
+			files, err := generate(opts, configFormat)
+			if err != nil {
+				return err
+			}
+
+			ui.Info(fmt.Sprintf("Upgrading from template version %s to %s", m.Version, TemplateVersion))
+			paths := map[string]bool{}
+			for p := range m.Files {
+				paths[p] = true
+			}
+			for _, p := range files.paths {
+				paths[p] = true
+			}
+			sorted := make([]string, 0, len(paths))
+			for p := range paths {
+				sorted = append(sorted, p)
+			}
+			sort.Strings(sorted)
+
+			var changed, conflicted int
+			for _, p := range sorted {
+				action, err := upgradeFile(root, p, m.Files, files.content, dryRun, reject)
+				if err != nil {
+					return err
+				}
+				switch action {
+				case "":
+					continue
+				case "conflict":
+					conflicted++
+					if reject {
+						ui.Warning(p + ": conflicts with your changes; kept your version, see " + p + ".rej")
+					} else {
+						ui.Warning(p + ": conflicts with your changes; resolve the conflict markers")
+					}
+				case "removed":
+					ui.Println(p + ": no longer part of the template; left in place")
+				case "deleted":
+					ui.Println(p + ": deleted in the project; not restored")
+				default:
+					changed++
+					ui.Println(fmt.Sprintf("%-8s %s", action, p))
+				}
+			}
+
+			if dryRun {
+				ui.Info(fmt.Sprintf("Dry run: %d file(s) would change, %d with conflicts", changed, conflicted))
+				return nil
+			}
+			if err := writeManifest(root, opts, files); err != nil {
+				return err
+			}
+			if conflicted > 0 {
+				return fmt.Errorf("%d file(s) have conflicts", conflicted)
+			}
+			ui.Successf("Project upgraded to template version %s (%d file(s) changed)", TemplateVersion, changed)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would change without writing files")
+	cmd.Flags().BoolVar(&reject, "reject", false, "keep your version of conflicting regions and write the template change to <file>.rej")
+	return cmd
+}
+
+// upgradeFile reconciles one project file with the new template and
+// returns what was done: "" for nothing, "added", "updated", "merged",
+// "removed" (from the template, kept on disk), "deleted" (by the user, not
+// restored) or "conflict".
+func upgradeFile(root, p string, old map[string]string, current map[string][]byte, dryRun, reject bool) (string, error) {
+	base, hasBase := old[p]
+	newContent, hasNew := current[p]
+	theirs := string(newContent)
+	target := filepath.Join(root, filepath.FromSlash(p))
+
+	b, err := os.ReadFile(target)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	ours := string(b)
+
+	switch {
+	case hasBase && hasNew && theirs == base:
+		// the template did not change
+		return "", nil
+	case !hasNew:
+		if exists && ours == base {
+			return "removed", nil
+		}
+		return "", nil
+	case !exists && hasBase:
+		return "deleted", nil
+	case !exists:
+		return "added", write(target, theirs, dryRun)
+	case ours == theirs:
+		return "", nil
+	case hasBase && ours == base:
+		return "updated", write(target, theirs, dryRun)
+	}
+
+	emit := conflictMarkers
+	if reject {
+		emit = keepOurs
+	}
+	merged, conflicts := merge3(base, ours, theirs, emit)
+	if len(conflicts) == 0 {
+		return "merged", write(target, merged, dryRun)
+	}
+	if err := write(target, merged, dryRun); err != nil {
+		return "", err
+	}
+	if reject {
+		if err := write(target+".rej", rejects(p, conflicts), dryRun); err != nil {
+			return "", err
+		}
+	}
+	return "conflict", nil
+}
+
+func write(path, content string, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
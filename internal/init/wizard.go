@@ -0,0 +1,348 @@
+package initpkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// templateLocale is the locale the embedded resources/lang files are
+// authored in; they are copied to the directory of the chosen locale.
+const templateLocale = "en-US"
+
+// feature is an optional part of the generated project.
+type feature struct {
+	Name        string
+	Description string
+	Root        string   // template subtree only generated with the feature
+	Dirs        []string // directories created for the feature
+}
+
+var features = []feature{
+	{"database", "models, repositories, migrations and seeds", "database",
+		[]string{"database/model", "database/repository", "database/migrations", "database/seed"}},
+	{"validation", "request validation rules", "http/validation", []string{"http/validation"}},
+	{"middleware", "HTTP middleware", "http/middleware", []string{"http/middleware"}},
+	{"i18n", "translations under resources/lang", "resources/lang", []string{"resources/lang"}},
+	{"docker", "Dockerfile and compose.yaml", "", nil},
+}
+
+func featureNames() []string {
+	names := make([]string, len(features))
+	for i, f := range features {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// databaseTypes are the database choices offered by init.
+var databaseTypes = []string{"postgres", "mysql", "sqlite"}
+
+// database holds the answers used for the database section of the config.
+type database struct {
+	Type     string `json:"type"`
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"-"` // written to .env only; see writeDotEnv
+	Name     string `json:"name"`
+}
+
+// options are the answers that shape a generated project. They are recorded
+// in the project's .kygo manifest so upgrade-project can render the
+// templates again.
+type options struct {
+	Name       string            `json:"name"`
+	Module     string            `json:"module"`
+	Preset     string            `json:"preset,omitempty"`
+	Template   string            `json:"template,omitempty"` // external template, see loadTemplate
+	ConfigFile string            `json:"config_file"`
+	Locale     string            `json:"locale"`
+	Database   *database         `json:"database,omitempty"`
+	Features   map[string]bool   `json:"features"`
+	Vars       map[string]string `json:"vars,omitempty"`
+
+	preset  *preset
+	cleanup func()
+}
+
+// skipped reports whether the template path rel belongs to a feature that
+// was not selected. Templates whose manifest doesn't list features are
+// copied whole.
+func (o *options) skipped(rel string) bool {
+	if o.preset.Manifest.Features == nil {
+		return false
+	}
+	for _, f := range features {
+		if o.Features[f.Name] || f.Root == "" {
+			continue
+		}
+		if rel == f.Root || strings.HasPrefix(rel, f.Root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// dirs returns the directories to create for the selected features.
+func (o *options) dirs() []string {
+	dirs := append([]string(nil), o.preset.Manifest.Dirs...)
+	for _, f := range features {
+		if o.Features[f.Name] {
+			dirs = append(dirs, f.Dirs...)
+		}
+	}
+	if o.Features["i18n"] {
+		dirs = append(dirs, path.Join("resources/lang", o.Locale))
+	}
+	return dirs
+}
+
+// addWizardFlags registers a flag for every question the wizard asks.
+func addWizardFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.Bool("no-interaction", false, "do not prompt; use flags and defaults")
+	f.String("preset", defaultPreset, "project preset ("+strings.Join(presetNames(), ", ")+")")
+	f.String("template", "", "external template directory, or git+<url>[#ref]")
+	f.StringToString("var", nil, "template variable as name=value (repeatable)")
+	f.String("module", "", "Go module path (default: the project name)")
+	f.String("db-type", "postgres", "database type ("+strings.Join(databaseTypes, ", ")+")")
+	f.String("db-host", "localhost", "database host")
+	f.Int("db-port", 0, "database port (default: the standard port for --db-type)")
+	f.String("db-user", "", "database user (default: the project name)")
+	f.String("db-password", "", "database password")
+	f.String("db-name", "", "database name, or file for sqlite (default: the project name)")
+	f.String("locale", templateLocale, "default locale")
+	f.Bool("docker", false, "add a Dockerfile and compose.yaml (same as adding the docker feature)")
+	f.StringSlice("features", nil, "optional features ("+strings.Join(featureNames(), ", ")+"; default: those of the preset)")
+}
+
+// resolveOptions builds the project options from flags, asking for every
+// value that wasn't given on the command line when running interactively.
+func resolveOptions(cmd *cobra.Command, name string) (opts *options, err error) {
+	f := cmd.Flags()
+	noInteraction, _ := f.GetBool("no-interaction")
+	interactive := !noInteraction && ui.Interactive()
+	ask := func(flag string) bool { return interactive && !f.Changed(flag) }
+	// --docker answers the docker feature question
+	askFeature := func(name string) bool {
+		return ask("features") && !(name == "docker" && f.Changed("docker"))
+	}
+
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	o := &options{Name: base, Features: map[string]bool{}, Vars: map[string]string{}, cleanup: func() {}}
+	defer func() {
+		if err != nil {
+			o.cleanup()
+		}
+	}()
+
+	o.Module, _ = f.GetString("module")
+	if o.Module == "" {
+		o.Module = base
+	}
+	if ask("module") {
+		o.Module = ui.Prompt("Go module path", o.Module)
+	}
+
+	o.Preset, _ = f.GetString("preset")
+	if src, _ := f.GetString("template"); src != "" {
+		if f.Changed("preset") {
+			return nil, fmt.Errorf("--preset and --template cannot be combined")
+		}
+		// local templates are recorded by absolute path so upgrade-project
+		// finds them from the project directory
+		if !strings.HasPrefix(src, "git+") {
+			if abs, err := filepath.Abs(src); err == nil {
+				src = abs
+			}
+		}
+		p, cleanup, err := loadTemplate(src)
+		if err != nil {
+			return nil, err
+		}
+		o.Preset, o.Template, o.preset, o.cleanup = "", src, p, cleanup
+	} else if ask("preset") {
+		names := presetNames()
+		labels := make([]string, len(names))
+		def := o.Preset
+		for i, n := range names {
+			labels[i] = n
+			if p, err := loadPreset(n); err == nil && p.Manifest.Description != "" {
+				labels[i] = n + " - " + p.Manifest.Description
+			}
+			if n == o.Preset {
+				def = labels[i]
+			}
+		}
+		choice := ui.Select("Project preset", labels, def)
+		o.Preset, _, _ = strings.Cut(choice, " - ")
+	}
+	if o.preset == nil {
+		p, err := loadPreset(o.Preset)
+		if err != nil {
+			return nil, err
+		}
+		o.preset = p
+	}
+	if err := o.resolveVars(f, interactive); err != nil {
+		return nil, err
+	}
+
+	o.Locale, _ = f.GetString("locale")
+	if ask("locale") {
+		o.Locale = ui.Prompt("Default locale", o.Locale)
+	}
+
+	selected := o.preset.Manifest.Features
+	if f.Changed("features") {
+		selected, _ = f.GetStringSlice("features")
+	}
+	if withDocker, _ := f.GetBool("docker"); withDocker {
+		selected = append(selected, "docker")
+	}
+	for _, s := range selected {
+		o.Features[strings.TrimSpace(s)] = true
+	}
+	for name := range o.Features {
+		if !contains(featureNames(), name) {
+			return nil, fmt.Errorf("unknown feature %q (available: %s)", name, strings.Join(featureNames(), ", "))
+		}
+	}
+	for _, ft := range features {
+		if askFeature(ft.Name) {
+			o.Features[ft.Name] = ui.Confirm(fmt.Sprintf("Include %s (%s)?", ft.Name, ft.Description), o.Features[ft.Name])
+		}
+	}
+
+	if !o.Features["database"] {
+		return o, nil
+	}
+	db := &database{}
+	db.Type, _ = f.GetString("db-type")
+	if ask("db-type") {
+		db.Type = ui.Select("Database type", databaseTypes, db.Type)
+	}
+	if !contains(databaseTypes, db.Type) {
+		return nil, fmt.Errorf("unsupported --db-type %q (use %s)", db.Type, strings.Join(databaseTypes, ", "))
+	}
+	db.Name, _ = f.GetString("db-name")
+	if db.Type == "sqlite" {
+		if db.Name == "" {
+			db.Name = "database/" + base + ".db"
+		}
+		if ask("db-name") {
+			db.Name = ui.Prompt("Database file", db.Name)
+		}
+		o.Database = db
+		return o, nil
+	}
+
+	db.Host, _ = f.GetString("db-host")
+	if ask("db-host") {
+		db.Host = ui.Prompt("Database host", db.Host)
+	}
+	db.Port, _ = f.GetInt("db-port")
+	if db.Port == 0 {
+		db.Port = 5432
+		if db.Type == "mysql" {
+			db.Port = 3306
+		}
+	}
+	if ask("db-port") {
+		for {
+			v := ui.Prompt("Database port", strconv.Itoa(db.Port))
+			p, err := strconv.Atoi(v)
+			if err == nil && p > 0 && p <= 65535 {
+				db.Port = p
+				break
+			}
+			ui.Errorf("%q is not a valid port", v)
+		}
+	}
+	db.User, _ = f.GetString("db-user")
+	if db.User == "" {
+		db.User = identifier(base)
+	}
+	if ask("db-user") {
+		db.User = ui.Prompt("Database user", db.User)
+	}
+	db.Password, _ = f.GetString("db-password")
+	if ask("db-password") {
+		db.Password = ui.PromptSecret("Database password", db.Password)
+	}
+	if db.Name == "" {
+		db.Name = identifier(base)
+	}
+	if ask("db-name") {
+		db.Name = ui.Prompt("Database name", db.Name)
+	}
+	o.Database = db
+	return o, nil
+}
+
+// resolveVars fills Vars from --var, the answers to the template's prompts
+// and the variables' defaults.
+func (o *options) resolveVars(f *pflag.FlagSet, interactive bool) error {
+	given, _ := f.GetStringToString("var")
+	declared := map[string]bool{}
+	for _, v := range o.preset.Manifest.Variables {
+		declared[v.Name] = true
+		value, ok := given[v.Name]
+		if !ok {
+			value = v.Default
+			if interactive && v.Prompt != "" {
+				if len(v.Options) > 0 {
+					value = ui.Select(v.Prompt, v.Options, value)
+				} else {
+					value = ui.Prompt(v.Prompt, value)
+				}
+			}
+		}
+		if len(v.Options) > 0 && !contains(v.Options, value) {
+			return fmt.Errorf("variable %s must be one of %s, got %q", v.Name, strings.Join(v.Options, ", "), value)
+		}
+		o.Vars[v.Name] = value
+	}
+	for name := range given {
+		if !declared[name] {
+			return fmt.Errorf("unknown template variable %q", name)
+		}
+	}
+	return nil
+}
+
+// identifier turns a project name into a database-friendly identifier.
+func identifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// templateFuncs are available to every project template.
+var templateFuncs = map[string]any{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
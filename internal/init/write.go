@@ -0,0 +1,86 @@
+package initpkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writer writes generated files, protecting files that already exist in the
+// target directory unless overwriting was asked for.
+type writer struct {
+	root  string
+	merge bool // keep existing files, report the ones that differ
+	force bool // overwrite existing files
+
+	Conflicts []string // existing files that differ from the template
+	Kept      int      // existing files identical to the template
+}
+
+func newWriter(root string, merge, force bool) (*writer, error) {
+	if merge && force {
+		return nil, errors.New("--merge and --force cannot be combined")
+	}
+	w := &writer{root: root, merge: merge, force: force}
+	if merge || force {
+		return w, nil
+	}
+	empty, err := isEmptyDir(root)
+	if err != nil {
+		return nil, err
+	}
+	if !empty {
+		return nil, fmt.Errorf("%s is not empty; use --merge to add only missing files or --force to overwrite", root)
+	}
+	return w, nil
+}
+
+// write stores content at path unless a file already exists there and
+// overwriting wasn't asked for.
+func (w *writer) write(path string, content []byte) error {
+	return w.writeFile(path, content, 0644)
+}
+
+// writeFile is write with the permissions of a new file.
+func (w *writer) writeFile(path string, content []byte, perm os.FileMode) error {
+	if !w.force {
+		existing, err := os.ReadFile(path)
+		if err == nil {
+			rel, _ := filepath.Rel(w.root, path)
+			if bytes.Equal(existing, content) {
+				w.Kept++
+			} else {
+				w.Conflicts = append(w.Conflicts, filepath.ToSlash(rel))
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, perm)
+}
+
+// isEmptyDir reports whether dir is missing or has no entries.
+func isEmptyDir(dir string) (bool, error) {
+	f, err := os.Open(dir)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := f.Readdirnames(1); err == io.EOF {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return false, nil
+}
@@ -0,0 +1,340 @@
+package lang
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// LangCmd returns the `lang` command group.
+func LangCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "lang",
+		Short: "Manage translation files",
+		Long: "Manage the translation files under resources/lang (dirs.lang in kygo.json): one\n" +
+			"directory per locale holding JSON files such as locale.json, fields.json and rules.json.",
+	}
+	root.AddCommand(makeAddCmd(), makeMissingCmd(), makeNormalizeCmd("sort", true), makeNormalizeCmd("format", false), makeExtractCmd())
+	return root
+}
+
+// loadCatalog loads the project's translations.
+func loadCatalog() (*Catalog, error) {
+	proj, err := project.Current()
+	if err != nil {
+		return nil, err
+	}
+	dir := project.Path(proj.Dirs.Lang)
+	c, err := Load(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no translations found: %s does not exist", dir)
+	}
+	return c, err
+}
+
+// defaultLocale is app.language from the config, or the example config,
+// when the catalog has it, and the locale with the most keys otherwise.
+func defaultLocale(c *Catalog) string {
+	cfg, err := config.Load("")
+	if err != nil || cfg.App.Language == "" {
+		cfg, err = config.ReadFile(config.DiscoverExample(""))
+	}
+	if err == nil && c.Has(cfg.App.Language) {
+		return cfg.App.Language
+	}
+	return c.Largest()
+}
+
+func makeAddCmd() *cobra.Command {
+	var from string
+	cmd := &cobra.Command{
+		Use:   "add <locale>",
+		Short: "Add a locale with the keys of an existing one and empty translations",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			locale := args[0]
+			if locale == "" || strings.ContainsAny(locale, `/\`) || strings.HasPrefix(locale, ".") {
+				return fmt.Errorf("invalid locale %q", locale)
+			}
+			c, err := loadCatalog()
+			if err != nil {
+				return err
+			}
+			if c.Has(locale) {
+				return fmt.Errorf("locale %s already exists in %s", locale, c.Dir)
+			}
+			if from == "" {
+				from = defaultLocale(c)
+			}
+			if !c.Has(from) {
+				return fmt.Errorf("no locale %q to copy from (available: %s)", from, strings.Join(c.Locales, ", "))
+			}
+			c.Files[locale] = map[string]*Object{}
+			for name, o := range c.Files[from] {
+				c.Files[locale][name] = Blank(o)
+			}
+			if len(c.Files[locale]) == 0 {
+				return fmt.Errorf("locale %s has no translation files", from)
+			}
+			for _, name := range c.FileNames() {
+				if _, ok := c.Files[locale][name]; !ok {
+					continue
+				}
+				if err := c.Save(locale, name); err != nil {
+					return err
+				}
+			}
+			ui.Successf("Created %s from %s; fill in the empty translations", filepath.Join(c.Dir, locale), from)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "locale whose keys are copied (default: app.language, or the locale with the most keys)")
+	return cmd
+}
+
+func makeMissingCmd() *cobra.Command {
+	var empty bool
+	cmd := &cobra.Command{
+		Use:   "missing",
+		Short: "Report keys that some locales have and others lack",
+		Long: "Compare every locale's files key by key, nested keys included, and list for each\n" +
+			"locale the keys another locale has. Exits non-zero when any key is missing.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadCatalog()
+			if err != nil {
+				return err
+			}
+			missing := c.Missing(empty)
+			total := 0
+			for _, locale := range c.Locales {
+				keys := missing[locale]
+				if len(keys) == 0 {
+					continue
+				}
+				total += len(keys)
+				ui.Warning(fmt.Sprintf("%s is missing %d key(s):", locale, len(keys)))
+				for _, k := range keys {
+					ui.Println("  " + k)
+				}
+			}
+			if total > 0 {
+				return fmt.Errorf("%d missing translation(s)", total)
+			}
+			ui.Successf("All %d locale(s) have the same keys", len(c.Locales))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&empty, "empty", false, "also report keys whose translation is empty")
+	return cmd
+}
+
+// makeNormalizeCmd returns `lang sort` (withSort) or `lang format`, which
+// rewrite files with two-space indentation and a trailing newline; sort
+// also orders keys alphabetically at every level.
+func makeNormalizeCmd(name string, withSort bool) *cobra.Command {
+	var check bool
+	short := "Rewrite translation files with consistent formatting"
+	if withSort {
+		short = "Sort the keys of translation files alphabetically and format them"
+	}
+	cmd := &cobra.Command{
+		Use:   name + " [locale...]",
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadCatalog()
+			if err != nil {
+				return err
+			}
+			locales := c.Locales
+			if len(args) > 0 {
+				for _, l := range args {
+					if !c.Has(l) {
+						return fmt.Errorf("unknown locale %q (available: %s)", l, strings.Join(c.Locales, ", "))
+					}
+				}
+				locales = args
+			}
+			changed := 0
+			for _, locale := range locales {
+				for _, file := range c.FileNames() {
+					o, ok := c.Files[locale][file]
+					if !ok {
+						continue
+					}
+					if withSort {
+						o.Sort()
+					}
+					want, err := o.Marshal()
+					if err != nil {
+						return err
+					}
+					path := c.Path(locale, file)
+					have, err := os.ReadFile(path)
+					if err != nil {
+						return err
+					}
+					if bytes.Equal(have, want) {
+						continue
+					}
+					changed++
+					if check {
+						ui.Println(path)
+						continue
+					}
+					if err := os.WriteFile(path, want, 0644); err != nil {
+						return err
+					}
+					ui.Println("  " + path)
+				}
+			}
+			switch {
+			case check && changed > 0:
+				return fmt.Errorf("%d file(s) need `kygo lang %s`", changed, name)
+			case check:
+				ui.Success("Translation files are normalised")
+			default:
+				ui.Successf("%d file(s) rewritten", changed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&check, "check", false, "list files that would change and exit non-zero instead of rewriting them")
+	return cmd
+}
+
+func makeExtractCmd() *cobra.Command {
+	var prune, dryRun bool
+	cmd := &cobra.Command{
+		Use:   "extract",
+		Short: "Add the translation keys used in the code to every locale and report unused ones",
+		Long: "Scan the project's Go files for translation lookups with a constant key (Translate,\n" +
+			"Trans and T calls) and for `field` tags of the structs in dirs.validation, add the keys\n" +
+			"missing from each locale with empty translations, and list the keys nothing refers to.\n" +
+			"Keys starting with a file name (fields.user.email) go to that file, others to locale.json.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			proj, err := project.Current()
+			if err != nil {
+				return err
+			}
+			c, err := loadCatalog()
+			if err != nil {
+				return err
+			}
+			if len(c.Locales) == 0 {
+				return fmt.Errorf("no locales in %s; create a directory per locale, e.g. %s", c.Dir, filepath.Join(c.Dir, "en-US"))
+			}
+			u, err := Extract(project.Root(), project.Path(proj.Dirs.Validation))
+			if err != nil {
+				return err
+			}
+			for _, pos := range u.Dynamic {
+				ui.Warning(pos + ": the translation key is not a constant; add it by hand and check --prune does not remove it")
+			}
+
+			changed := map[string]map[string]bool{} // locale -> file
+			mark := func(locale, file string) {
+				if changed[locale] == nil {
+					changed[locale] = map[string]bool{}
+				}
+				changed[locale][file] = true
+			}
+			added, seen := 0, map[string]bool{}
+			for _, r := range u.Refs {
+				file, path := c.Split(r.Key)
+				key := Key(file, path)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				parts := strings.Split(path, ".")
+				var in []string
+				for _, locale := range c.Locales {
+					o := c.Files[locale][file]
+					if o == nil {
+						o = NewObject()
+						c.Files[locale][file] = o
+					}
+					if _, ok := Lookup(o, parts); ok {
+						continue
+					}
+					if !Insert(o, parts, "") {
+						ui.Warning(fmt.Sprintf("%s: cannot add %s to %s: part of the key is already a translation", r.Pos, key, locale))
+						continue
+					}
+					mark(locale, file)
+					in = append(in, locale)
+				}
+				if len(in) > 0 {
+					added++
+					ui.Println(fmt.Sprintf("  + %s (%s) in %s", key, r.Pos, strings.Join(in, ", ")))
+				}
+			}
+
+			unused := c.Unused(u)
+			if len(unused) > 0 {
+				if prune {
+					ui.Info(fmt.Sprintf("Removing %d unused key(s):", len(unused)))
+				} else {
+					ui.Warning(fmt.Sprintf("%d key(s) are not referenced in the code (remove them with --prune):", len(unused)))
+				}
+				for _, k := range unused {
+					ui.Println("  - " + k)
+				}
+			}
+			if prune {
+				drop := map[string]bool{}
+				for _, k := range unused {
+					drop[k] = true
+				}
+				for _, locale := range c.Locales {
+					for file, o := range c.Files[locale] {
+						for _, p := range Paths(o) {
+							if drop[Key(file, strings.Join(p, "."))] {
+								Remove(o, p)
+								mark(locale, file)
+							}
+						}
+					}
+				}
+			}
+
+			files := 0
+			for _, locale := range c.Locales {
+				for _, file := range c.FileNames() {
+					if !changed[locale][file] {
+						continue
+					}
+					files++
+					if dryRun {
+						continue
+					}
+					if err := c.Save(locale, file); err != nil {
+						return err
+					}
+				}
+			}
+			summary := fmt.Sprintf("%d key(s) used in the code, %d added", len(seen), added)
+			if dryRun {
+				ui.Successf("%s; %d file(s) would change (dry run)", summary, files)
+			} else {
+				ui.Successf("%s; %d file(s) updated", summary, files)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&prune, "prune", false, "remove keys that are not referenced in the code (rules.json is never pruned)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report the changes without writing the files")
+	return cmd
+}
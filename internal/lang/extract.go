@@ -0,0 +1,227 @@
+package lang
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Translation files kyugo reads: general messages, validation field names
+// and validation rule messages.
+const (
+	LocaleFile = "locale.json"
+	FieldsFile = "fields.json"
+	RulesFile  = "rules.json"
+)
+
+// translateFuncs are the names of kyugo's translation lookups, called as
+// methods (req.Translate, ctx.T) or package functions.
+var translateFuncs = map[string]bool{"Translate": true, "Trans": true, "T": true}
+
+// Reference is a translation key used in the code.
+type Reference struct {
+	Key string
+	Pos string // file:line relative to the scanned root
+}
+
+// Usage is what Extract finds in the code.
+type Usage struct {
+	Refs []Reference
+	// Prefixes are the constant parts of keys built at run time, e.g.
+	// "errors." for T("errors." + code); keys under them count as used.
+	Prefixes []string
+	// Dynamic are the positions of lookups whose key can't be read at all.
+	Dynamic []string
+}
+
+// Extract scans the Go files under root for translation lookups with a
+// constant key, and for `field` tags of the structs in validationDir, whose
+// values name entries of fields.json.
+func Extract(root, validationDir string) (*Usage, error) {
+	u := &Usage{}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		validation := within(p, validationDir)
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				u.call(fset, root, n)
+			case *ast.StructType:
+				if validation {
+					u.fields(fset, root, n)
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(u.Prefixes)
+	return u, nil
+}
+
+func (u *Usage) call(fset *token.FileSet, root string, call *ast.CallExpr) {
+	var name string
+	switch fn := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		name = fn.Sel.Name
+	case *ast.Ident:
+		name = fn.Name
+	}
+	if !translateFuncs[name] || len(call.Args) == 0 {
+		return
+	}
+	if key, ok := stringLit(call.Args[0]); ok {
+		u.Refs = append(u.Refs, Reference{Key: key, Pos: position(fset, root, call.Pos())})
+		return
+	}
+	if prefix, ok := concatPrefix(call.Args[0]); ok && prefix != "" {
+		u.Prefixes = append(u.Prefixes, prefix)
+		return
+	}
+	u.Dynamic = append(u.Dynamic, position(fset, root, call.Pos()))
+}
+
+func (u *Usage) fields(fset *token.FileSet, root string, st *ast.StructType) {
+	for _, f := range st.Fields.List {
+		if f.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			continue
+		}
+		name := reflect.StructTag(tag).Get("field")
+		if name == "" || name == "-" {
+			continue
+		}
+		name = strings.TrimPrefix(name, strings.TrimSuffix(FieldsFile, ".json")+".")
+		u.Refs = append(u.Refs, Reference{Key: Key(FieldsFile, name), Pos: position(fset, root, f.Pos())})
+	}
+}
+
+// stringLit returns the value of a string literal.
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// concatPrefix returns the leading string literal of "a." + b + ...
+func concatPrefix(e ast.Expr) (string, bool) {
+	for {
+		bin, ok := e.(*ast.BinaryExpr)
+		if !ok || bin.Op != token.ADD {
+			break
+		}
+		e = bin.X
+	}
+	return stringLit(e)
+}
+
+func position(fset *token.FileSet, root string, pos token.Pos) string {
+	p := fset.Position(pos)
+	name := p.Filename
+	if r, err := filepath.Rel(root, name); err == nil {
+		name = r
+	}
+	return fmt.Sprintf("%s:%d", filepath.ToSlash(name), p.Line)
+}
+
+// within reports whether file p is inside dir.
+func within(p, dir string) bool {
+	r, err := filepath.Rel(dir, p)
+	return err == nil && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator))
+}
+
+// Split maps a key used in the code to a translation file and the dotted
+// path within it. A key starting with the name of a translation file, as
+// in fields.user.email, is looked up in that file; any other key, such as
+// errors.not_found, is a message in locale.json.
+func (c *Catalog) Split(key string) (file, path string) {
+	first, rest, ok := strings.Cut(key, ".")
+	if ok {
+		file := first + ".json"
+		if file == LocaleFile || file == FieldsFile || file == RulesFile {
+			return file, rest
+		}
+		for _, name := range c.FileNames() {
+			if name == file {
+				return file, rest
+			}
+		}
+	}
+	return LocaleFile, key
+}
+
+// Unused returns the keys in any locale that neither u nor the validator
+// refers to, sorted. rules.json is left out: kyugo reads its messages for
+// whichever validation rule fails.
+func (c *Catalog) Unused(u *Usage) []string {
+	used := map[string]bool{}
+	for _, r := range u.Refs {
+		used[Key(c.Split(r.Key))] = true
+	}
+	var prefixes []string
+	for _, p := range u.Prefixes {
+		file, path := c.Split(p)
+		prefixes = append(prefixes, Key(file, path))
+	}
+	seen := map[string]bool{}
+	var unused []string
+	for _, locale := range c.Locales {
+		for file, o := range c.Files[locale] {
+			if file == RulesFile {
+				continue
+			}
+			for _, p := range Flatten(o) {
+				k := Key(file, p)
+				if used[k] || seen[k] || hasPrefix(k, prefixes) {
+					continue
+				}
+				seen[k] = true
+				unused = append(unused, k)
+			}
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+func hasPrefix(k string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+	return false
+}
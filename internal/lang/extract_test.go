@@ -0,0 +1,193 @@
+package lang
+
+import (
+	"go/parser"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-kyugo/kygo/internal/project"
+)
+
+// testdata/extract is a small project: translation lookups in main.go and
+// service/, `field` tags in http/validation and outside it, and a catalog
+// with used, unused, prefixed and rules.json keys.
+const extractProject = "testdata/extract"
+
+func extractTestdata(t *testing.T) (*Catalog, *Usage) {
+	t.Helper()
+	c, err := Load(filepath.Join(extractProject, "resources", "lang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := Extract(extractProject, filepath.Join(extractProject, "http", "validation"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, u
+}
+
+func TestExtract(t *testing.T) {
+	_, u := extractTestdata(t)
+	want := []Reference{
+		// the fields. prefix of a tag is optional
+		{Key: "fields.user.name", Pos: "http/validation/user.go:4"},
+		{Key: "fields.user.age", Pos: "http/validation/user.go:5"},
+		{Key: "greeting", Pos: "main.go:11"},
+		{Key: "fields.user.email", Pos: "main.go:12"},
+		{Key: "errors.not_found", Pos: "main.go:13"},
+		{Key: "auth.login", Pos: "service/errors.go:9"},
+	}
+	if !reflect.DeepEqual(u.Refs, want) {
+		t.Errorf("Refs:\n got %v\nwant %v", u.Refs, want)
+	}
+	if want := []string{"errors."}; !reflect.DeepEqual(u.Prefixes, want) {
+		t.Errorf("Prefixes = %v, want %v", u.Prefixes, want)
+	}
+	if want := []string{"service/errors.go:10"}; !reflect.DeepEqual(u.Dynamic, want) {
+		t.Errorf("Dynamic = %v, want %v", u.Dynamic, want)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	c, _ := extractTestdata(t)
+	tests := []struct {
+		key, file, path string
+	}{
+		{"fields.user.email", FieldsFile, "user.email"},
+		{"rules.required", RulesFile, "required"},
+		{"locale.greeting", LocaleFile, "greeting"},
+		{"auth.login", "auth.json", "login"}, // a file of one locale only
+		{"errors.not_found", LocaleFile, "errors.not_found"},
+		{"greeting", LocaleFile, "greeting"},
+		{"fields", LocaleFile, "fields"},
+	}
+	for _, tt := range tests {
+		file, path := c.Split(tt.key)
+		if file != tt.file || path != tt.path {
+			t.Errorf("Split(%q) = %q, %q, want %q, %q", tt.key, file, path, tt.file, tt.path)
+		}
+	}
+}
+
+func TestUnused(t *testing.T) {
+	c, u := extractTestdata(t)
+	// errors.timeout.title is under the errors. prefix, rules.json is never
+	// reported and fields.user.nickname is tagged outside dirs.validation
+	want := []string{"auth.logout", "fields.user.nickname", "fields.user.phone", "locale.stale"}
+	if got := c.Unused(u); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unused = %v, want %v", got, want)
+	}
+
+	// without the prefix the keys built at run time look unused
+	u.Prefixes = nil
+	want = []string{"auth.logout", "fields.user.nickname", "fields.user.phone", "locale.errors.timeout.title", "locale.stale"}
+	if got := c.Unused(u); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unused without prefixes = %v, want %v", got, want)
+	}
+}
+
+func TestConcatPrefix(t *testing.T) {
+	tests := []struct {
+		expr   string
+		prefix string
+		ok     bool
+	}{
+		{`"errors." + code`, "errors.", true},
+		{`"errors." + code + ".title"`, "errors.", true},
+		{`"errors"`, "errors", true},
+		{`code + ".title"`, "", false},
+		{`fmt.Sprintf("errors.%s", code)`, "", false},
+		{`"a" - b`, "", false},
+	}
+	for _, tt := range tests {
+		e, err := parser.ParseExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		prefix, ok := concatPrefix(e)
+		if prefix != tt.prefix || ok != tt.ok {
+			t.Errorf("concatPrefix(%s) = %q, %v, want %q, %v", tt.expr, prefix, ok, tt.prefix, tt.ok)
+		}
+	}
+}
+
+// TestExtractPrune runs `lang extract --prune` on a copy of the project and
+// checks what it adds and removes.
+func TestExtractPrune(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS(extractProject)); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	if err := project.SetDir("."); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { project.SetDir(".") })
+	rules, err := os.ReadFile(filepath.Join(dir, "resources", "lang", "en-US", RulesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := makeExtractCmd()
+	cmd.SetArgs([]string{"--prune"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"en-US/locale.json": `{
+  "greeting": "Hello",
+  "errors": {
+    "not_found": "Not found",
+    "timeout": {
+      "title": "Timed out"
+    }
+  }
+}
+`,
+		"en-US/fields.json": `{
+  "user": {
+    "email": "e-mail",
+    "name": "name",
+    "age": "age"
+  }
+}
+`,
+		"en-US/auth.json": `{
+  "login": ""
+}
+`,
+		"en-US/rules.json": string(rules),
+		"pt-BR/locale.json": `{
+  "greeting": "Olá",
+  "errors": {
+    "not_found": ""
+  }
+}
+`,
+		"pt-BR/fields.json": `{
+  "user": {
+    "email": "e-mail",
+    "name": "",
+    "age": ""
+  }
+}
+`,
+		"pt-BR/auth.json": `{
+  "login": "Entrar"
+}
+`,
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, "resources", "lang", filepath.FromSlash(name)))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s:\n%s\nwant:\n%s", name, got, content)
+		}
+	}
+}
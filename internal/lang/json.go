@@ -0,0 +1,286 @@
+package lang
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Object is a JSON object that remembers the order of its keys, so files
+// are rewritten the way they were authored unless asked to sort them.
+// Values are strings (translations), nested *Object groups or any other
+// JSON value, kept as decoded.
+type Object struct {
+	keys   []string
+	values map[string]any
+}
+
+// NewObject returns an empty object.
+func NewObject() *Object {
+	return &Object{values: map[string]any{}}
+}
+
+// Keys returns the keys in order.
+func (o *Object) Keys() []string { return o.keys }
+
+// Get returns the value of key.
+func (o *Object) Get(key string) (any, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// Set sets key, appending it when new.
+func (o *Object) Set(key string, v any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+// Delete removes key.
+func (o *Object) Delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Len is the number of keys.
+func (o *Object) Len() int { return len(o.keys) }
+
+// Sort orders the keys of o and every nested object alphabetically.
+func (o *Object) Sort() {
+	sort.Strings(o.keys)
+	for _, v := range o.values {
+		if child, ok := v.(*Object); ok {
+			child.Sort()
+		}
+	}
+}
+
+// Parse decodes a JSON object, keeping key order.
+func Parse(b []byte) (*Object, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	v, err := parseValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, fmt.Errorf("unexpected data after the top-level object")
+	}
+	o, ok := v.(*Object)
+	if !ok {
+		return nil, fmt.Errorf("top-level value must be an object")
+	}
+	return o, nil
+}
+
+func parseValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch d {
+	case '{':
+		o := NewObject()
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := tok.(string)
+			v, err := parseValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			o.Set(key, v)
+		}
+		_, err := dec.Token() // }
+		return o, err
+	case '[':
+		var list []any
+		for dec.More() {
+			v, err := parseValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		_, err := dec.Token() // ]
+		return list, err
+	}
+	return nil, fmt.Errorf("unexpected %v", d)
+}
+
+// Marshal encodes o with two-space indentation and a trailing newline,
+// leaving <, > and & unescaped as they are common in messages.
+func (o *Object) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeValue(&buf, o, ""); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeValue(buf *bytes.Buffer, v any, indent string) error {
+	switch v := v.(type) {
+	case *Object:
+		if v.Len() == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i, k := range v.keys {
+			buf.WriteString(indent + "  ")
+			if err := writeScalar(buf, k); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			if err := writeValue(buf, v.values[k], indent+"  "); err != nil {
+				return err
+			}
+			if i < len(v.keys)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, item := range v {
+			buf.WriteString(indent + "  ")
+			if err := writeValue(buf, item, indent+"  "); err != nil {
+				return err
+			}
+			if i < len(v)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	default:
+		return writeScalar(buf, v)
+	}
+	return nil
+}
+
+func writeScalar(buf *bytes.Buffer, v any) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimRight(b.Bytes(), "\n"))
+	return nil
+}
+
+// Flatten returns the dotted path of every leaf of o, in order. Empty
+// objects count as no keys. Keys may contain dots themselves; use Paths to
+// tell a.b from {"a": {"b": ...}}.
+func Flatten(o *Object) []string {
+	var keys []string
+	for _, p := range Paths(o) {
+		keys = append(keys, strings.Join(p, "."))
+	}
+	return keys
+}
+
+// Paths returns the path of every leaf of o as its keys, in order.
+func Paths(o *Object) [][]string {
+	var paths [][]string
+	walkPaths(o, nil, &paths)
+	return paths
+}
+
+func walkPaths(o *Object, prefix []string, paths *[][]string) {
+	for _, k := range o.keys {
+		p := append(append([]string{}, prefix...), k)
+		if child, ok := o.values[k].(*Object); ok {
+			walkPaths(child, p, paths)
+			continue
+		}
+		*paths = append(*paths, p)
+	}
+}
+
+// Lookup returns the value at a path of keys.
+func Lookup(o *Object, path []string) (any, bool) {
+	v, ok := o.Get(path[0])
+	if !ok || len(path) == 1 {
+		return v, ok
+	}
+	child, ok := v.(*Object)
+	if !ok {
+		return nil, false
+	}
+	return Lookup(child, path[1:])
+}
+
+// Insert sets the value at path, creating groups on the way.
+// It returns false when a prefix of the path is a translation rather than
+// a group.
+func Insert(o *Object, path []string, v any) bool {
+	if len(path) == 1 {
+		o.Set(path[0], v)
+		return true
+	}
+	existing, ok := o.Get(path[0])
+	if !ok {
+		child := NewObject()
+		o.Set(path[0], child)
+		return Insert(child, path[1:], v)
+	}
+	child, ok := existing.(*Object)
+	if !ok {
+		return false
+	}
+	return Insert(child, path[1:], v)
+}
+
+// Remove deletes the value at path and any group left empty.
+func Remove(o *Object, path []string) {
+	if len(path) == 1 {
+		o.Delete(path[0])
+		return
+	}
+	child, ok := o.values[path[0]].(*Object)
+	if !ok {
+		return
+	}
+	Remove(child, path[1:])
+	if child.Len() == 0 {
+		o.Delete(path[0])
+	}
+}
+
+// Blank returns a copy of o with every translation replaced by "".
+func Blank(o *Object) *Object {
+	out := NewObject()
+	for _, k := range o.keys {
+		if child, ok := o.values[k].(*Object); ok {
+			out.Set(k, Blank(child))
+		} else {
+			out.Set(k, "")
+		}
+	}
+	return out
+}
@@ -0,0 +1,189 @@
+package lang
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustParse(t *testing.T, s string) *Object {
+	t.Helper()
+	o, err := Parse([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func marshal(t *testing.T, o *Object) string {
+	t.Helper()
+	b, err := o.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	tests := []string{
+		"{}\n",
+		`{
+  "zebra": "last in the alphabet, first in the file",
+  "apple": "<b>Tom & Jerry</b>",
+  "nested": {
+    "b": "quote \" and newline \n",
+    "a": {}
+  },
+  "count": 12,
+  "ratio": 1.50,
+  "enabled": true,
+  "none": null,
+  "list": [
+    "x",
+    {
+      "k": "v"
+    }
+  ],
+  "empty": [],
+  "a.b": "a key with a dot"
+}
+`,
+	}
+	for _, in := range tests {
+		if got := marshal(t, mustParse(t, in)); got != in {
+			t.Errorf("round trip:\n got %s\nwant %s", got, in)
+		}
+	}
+}
+
+func TestMarshalFormats(t *testing.T) {
+	o := mustParse(t, `{"b":{"d":"1","c":"2"},"a":"3"}`)
+	want := `{
+  "b": {
+    "d": "1",
+    "c": "2"
+  },
+  "a": "3"
+}
+`
+	if got := marshal(t, o); got != want {
+		t.Errorf("Marshal:\n%s\nwant:\n%s", got, want)
+	}
+	o.Sort()
+	want = `{
+  "a": "3",
+  "b": {
+    "c": "2",
+    "d": "1"
+  }
+}
+`
+	if got := marshal(t, o); got != want {
+		t.Errorf("Marshal after Sort:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{`[]`, `"text"`, `{"a": 1} {}`, `{"a": }`, ``} {
+		if _, err := Parse([]byte(in)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	o := mustParse(t, `{"a": {"b": "1", "c": {}}, "a.b": "2", "d": "3"}`)
+	want := [][]string{{"a", "b"}, {"a.b"}, {"d"}}
+	if got := Paths(o); !reflect.DeepEqual(got, want) {
+		t.Errorf("Paths = %q, want %q", got, want)
+	}
+	if got, want := Flatten(o), []string{"a.b", "a.b", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten = %q, want %q", got, want)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	o := mustParse(t, `{"a": {"b": "nested"}, "a.b": "dotted"}`)
+	tests := []struct {
+		path []string
+		want any
+		ok   bool
+	}{
+		{[]string{"a", "b"}, "nested", true},
+		{[]string{"a.b"}, "dotted", true},
+		{[]string{"a", "c"}, nil, false},
+		{[]string{"a.b", "c"}, nil, false},
+		{[]string{"x"}, nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := Lookup(o, tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q) = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		path []string
+		ok   bool
+		want string
+	}{
+		{"new group", `{"a": "1"}`, []string{"b", "c"}, true, `{"a": "1", "b": {"c": ""}}`},
+		{"existing group", `{"b": {"x": "1"}}`, []string{"b", "c"}, true, `{"b": {"x": "1", "c": ""}}`},
+		{"replace", `{"a": "1"}`, []string{"a"}, true, `{"a": ""}`},
+		{"dotted key", `{"a": "1"}`, []string{"b.c"}, true, `{"a": "1", "b.c": ""}`},
+		{"translation in the way", `{"a": "1"}`, []string{"a", "b"}, false, `{"a": "1"}`},
+	}
+	for _, tt := range tests {
+		o := mustParse(t, tt.in)
+		if ok := Insert(o, tt.path, ""); ok != tt.ok {
+			t.Errorf("%s: Insert = %v, want %v", tt.name, ok, tt.ok)
+		}
+		if got, want := marshal(t, o), marshal(t, mustParse(t, tt.want)); got != want {
+			t.Errorf("%s:\n%s\nwant:\n%s", tt.name, got, want)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		path []string
+		want string
+	}{
+		{"leaf", `{"a": "1", "b": "2"}`, []string{"a"}, `{"b": "2"}`},
+		{"keeps siblings", `{"a": {"b": "1", "c": "2"}}`, []string{"a", "b"}, `{"a": {"c": "2"}}`},
+		{"drops empty groups", `{"a": {"b": {"c": "1"}}, "d": "2"}`, []string{"a", "b", "c"}, `{"d": "2"}`},
+		{"dotted key", `{"a.b": "1", "a": {"b": "2"}}`, []string{"a.b"}, `{"a": {"b": "2"}}`},
+		{"missing", `{"a": "1"}`, []string{"a", "b"}, `{"a": "1"}`},
+	}
+	for _, tt := range tests {
+		o := mustParse(t, tt.in)
+		Remove(o, tt.path)
+		if got, want := marshal(t, o), marshal(t, mustParse(t, tt.want)); got != want {
+			t.Errorf("%s:\n%s\nwant:\n%s", tt.name, got, want)
+		}
+	}
+}
+
+func TestBlank(t *testing.T) {
+	o := mustParse(t, `{"b": "1", "a": {"d": "2", "c": 3}, "e": {}}`)
+	want := `{
+  "b": "",
+  "a": {
+    "d": "",
+    "c": ""
+  },
+  "e": {}
+}
+`
+	if got := marshal(t, Blank(o)); got != want {
+		t.Errorf("Blank:\n%s\nwant:\n%s", got, want)
+	}
+	if v, _ := o.Get("b"); v != "1" {
+		t.Errorf("Blank changed its argument: b = %v", v)
+	}
+}
@@ -0,0 +1,160 @@
+package lang
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Catalog is the translations of a project: one directory per locale under
+// the lang directory, each holding JSON files such as locale.json,
+// fields.json and rules.json.
+type Catalog struct {
+	Dir     string
+	Locales []string
+	// Files maps locale to file name (e.g. "fields.json") to its content.
+	Files map[string]map[string]*Object
+}
+
+// Load reads every locale in dir.
+func Load(dir string) (*Catalog, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &Catalog{Dir: dir, Files: map[string]map[string]*Object{}}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		locale := e.Name()
+		c.Locales = append(c.Locales, locale)
+		c.Files[locale] = map[string]*Object{}
+		paths, err := filepath.Glob(filepath.Join(dir, locale, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			b, err := os.ReadFile(p)
+			if err != nil {
+				return nil, err
+			}
+			o, err := Parse(b)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", p, err)
+			}
+			c.Files[locale][filepath.Base(p)] = o
+		}
+	}
+	sort.Strings(c.Locales)
+	return c, nil
+}
+
+// Path returns the path of a translation file.
+func (c *Catalog) Path(locale, file string) string {
+	return filepath.Join(c.Dir, locale, file)
+}
+
+// Save writes a translation file.
+func (c *Catalog) Save(locale, file string) error {
+	b, err := c.Files[locale][file].Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(c.Dir, locale), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.Path(locale, file), b, 0644)
+}
+
+// FileNames returns the names of the files of every locale, sorted.
+func (c *Catalog) FileNames() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, files := range c.Files {
+		for name := range files {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has reports whether the catalog has locale.
+func (c *Catalog) Has(locale string) bool {
+	_, ok := c.Files[locale]
+	return ok
+}
+
+// Largest returns the locale with the most keys, the natural source when
+// adding a locale.
+func (c *Catalog) Largest() string {
+	best, bestKeys := "", -1
+	for _, locale := range c.Locales {
+		n := 0
+		for _, o := range c.Files[locale] {
+			n += len(Flatten(o))
+		}
+		if n > bestKeys {
+			best, bestKeys = locale, n
+		}
+	}
+	return best
+}
+
+// Key names a translation: the file without .json and the dotted path
+// within it, e.g. fields.user.email.
+func Key(file, path string) string {
+	return strings.TrimSuffix(file, ".json") + "." + path
+}
+
+// Missing returns, for each locale lacking some, the keys that another
+// locale has, sorted. With empty, keys whose translation is an empty
+// string also count as missing.
+func (c *Catalog) Missing(empty bool) map[string][]string {
+	all := map[string][][]string{} // file -> union of paths, in first-seen order
+	seen := map[string]bool{}
+	for _, locale := range c.Locales {
+		for _, file := range c.FileNames() {
+			o, ok := c.Files[locale][file]
+			if !ok {
+				continue
+			}
+			for _, p := range Paths(o) {
+				id := file + "\x00" + strings.Join(p, "\x00")
+				if !seen[id] {
+					seen[id] = true
+					all[file] = append(all[file], p)
+				}
+			}
+		}
+	}
+	missing := map[string][]string{}
+	for _, locale := range c.Locales {
+		var keys []string
+		for file, paths := range all {
+			o := c.Files[locale][file]
+			for _, p := range paths {
+				if o == nil || !present(o, p, empty) {
+					keys = append(keys, Key(file, strings.Join(p, ".")))
+				}
+			}
+		}
+		if len(keys) > 0 {
+			sort.Strings(keys)
+			missing[locale] = keys
+		}
+	}
+	return missing
+}
+
+// present reports whether path p is translated in o.
+func present(o *Object, p []string, nonEmpty bool) bool {
+	v, ok := Lookup(o, p)
+	return ok && !(nonEmpty && v == "")
+}
@@ -0,0 +1,95 @@
+package lang
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// catalog builds a catalog from JSON per locale and file.
+func catalog(t *testing.T, files map[string]map[string]string) *Catalog {
+	t.Helper()
+	c := &Catalog{Files: map[string]map[string]*Object{}}
+	for locale, byName := range files {
+		c.Locales = append(c.Locales, locale)
+		c.Files[locale] = map[string]*Object{}
+		for name, s := range byName {
+			c.Files[locale][name] = mustParse(t, s)
+		}
+	}
+	sort.Strings(c.Locales)
+	return c
+}
+
+func TestMissing(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]map[string]string
+		empty bool
+		want  map[string][]string
+	}{
+		{
+			name: "same keys",
+			files: map[string]map[string]string{
+				"en": {"locale.json": `{"a": "A", "b": {"c": "C"}}`},
+				"pt": {"locale.json": `{"b": {"c": "c"}, "a": "a"}`},
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "nested and missing files",
+			files: map[string]map[string]string{
+				"en": {"locale.json": `{"a": "A", "b": {"c": "C", "d": "D"}}`, "fields.json": `{"name": "Name"}`},
+				"pt": {"locale.json": `{"a": "a", "b": {"c": "c"}, "e": "e"}`},
+			},
+			want: map[string][]string{
+				"en": {"locale.e"},
+				"pt": {"fields.name", "locale.b.d"},
+			},
+		},
+		{
+			// regression: "a.b" and {"a": {"b"}} used to be taken for the
+			// same key once flattened
+			name: "dotted keys",
+			files: map[string]map[string]string{
+				"en": {"locale.json": `{"a.b": "dotted"}`},
+				"pt": {"locale.json": `{"a": {"b": "nested"}}`},
+			},
+			want: map[string][]string{
+				"en": {"locale.a.b"},
+				"pt": {"locale.a.b"},
+			},
+		},
+		{
+			name: "dotted key present in both",
+			files: map[string]map[string]string{
+				"en": {"locale.json": `{"a.b": "x", "a": {"b": "y"}}`},
+				"pt": {"locale.json": `{"a": {"b": "y"}, "a.b": "x"}`},
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "empty translations are present",
+			files: map[string]map[string]string{
+				"en": {"locale.json": `{"a": "A"}`},
+				"pt": {"locale.json": `{"a": ""}`},
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "empty translations with --empty",
+			files: map[string]map[string]string{
+				"en": {"locale.json": `{"a": "A"}`},
+				"pt": {"locale.json": `{"a": ""}`},
+			},
+			empty: true,
+			want:  map[string][]string{"pt": {"locale.a"}},
+		},
+	}
+	for _, tt := range tests {
+		got := catalog(t, tt.files).Missing(tt.empty)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Missing = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package validation
+
+type CreateUser struct {
+	Name  string `json:"name" field:"fields.user.name"`
+	Age   int    `json:"age" field:"user.age"`
+	Token string `json:"token" field:"-"`
+	Notes string `json:"notes"`
+}
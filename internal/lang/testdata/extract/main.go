@@ -0,0 +1,14 @@
+package main
+
+type request struct{}
+
+func (request) Translate(key string) string { return key }
+
+func T(key string) string { return key }
+
+func main() {
+	var req request
+	req.Translate("greeting")
+	T("fields.user.email")
+	T("errors.not_found")
+}
@@ -0,0 +1,11 @@
+package service
+
+type context struct{}
+
+func (context) Trans(key string) string { return key }
+
+func failure(ctx context, code, key string) string {
+	ctx.Trans("errors." + code + ".title")
+	ctx.Trans("auth.login")
+	return ctx.Trans(key)
+}
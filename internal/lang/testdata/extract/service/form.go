@@ -0,0 +1,6 @@
+package service
+
+// outside the validation directory: field tags are not translation keys
+type form struct {
+	Nickname string `field:"user.nickname"`
+}
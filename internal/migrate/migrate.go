@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	mg "github.com/golang-migrate/migrate/v4"
+	mgdb "github.com/golang-migrate/migrate/v4/database"
 	_ "github.com/golang-migrate/migrate/v4/database/mysql"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
@@ -14,6 +15,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/project"
 	"github.com/go-kyugo/kygo/internal/ui"
 )
 
@@ -22,7 +24,7 @@ func Run(migrationsPath, database string, args ...string) error {
 		return fmt.Errorf("database URL is required (use --database or set DATABASE_URL)")
 	}
 
-	ui.Info(fmt.Sprintf("Running migrations from %s -> %s", migrationsPath, database))
+	ui.Info(fmt.Sprintf("Running migrations from %s -> %s", migrationsPath, config.MaskDSN(database)))
 	abs, err := filepath.Abs(migrationsPath)
 	if err != nil {
 		return err
@@ -116,29 +118,176 @@ func Run(migrationsPath, database string, args ...string) error {
 	}
 }
 
+// Status connects to database and returns the version recorded by the last
+// migration and whether it failed half-way (dirty). The version is
+// database.NilVersion when no migration has run.
+func Status(database string) (version int, dirty bool, err error) {
+	driver, err := mgdb.Open(database)
+	if err != nil {
+		return 0, false, err
+	}
+	defer driver.Close()
+	return driver.Version()
+}
+
+// target is a single migrations directory / database pair to run against.
+type target struct {
+	name     string
+	path     string
+	database string
+}
+
+// addTargetFlags registers the flags used to select which database the
+// command runs against. withAll adds --all for commands that can iterate
+// over every configured connection.
+func addTargetFlags(cmd *cobra.Command, withAll bool) {
+	cmd.Flags().String("path", "", "migrations directory (default from the connection config or kygo.json)")
+	cmd.Flags().String("database", "", "database URL (default from config.json, KYGO_DATABASE_* or DATABASE_URL)")
+	cmd.Flags().String("connection", "", "named database connection from config.json")
+	if withAll {
+		cmd.Flags().Bool("all", false, "run against every configured connection")
+	}
+}
+
+// resolveTargets works out the migrations path and database URL for each
+// connection selected by the command's flags. Explicit --path and --database
+// always win over config.json, which in turn wins over kygo.json and
+// DATABASE_URL.
+func resolveTargets(c *cobra.Command) ([]target, error) {
+	path, _ := c.Flags().GetString("path")
+	database, _ := c.Flags().GetString("database")
+	connection, _ := c.Flags().GetString("connection")
+	all, _ := c.Flags().GetBool("all")
+	pathSet := c.Flags().Changed("path")
+
+	proj, err := project.Current()
+	if err != nil {
+		return nil, err
+	}
+	// directories from config.json and kygo.json are relative to the
+	// project root; --path is relative to the working directory
+	base := proj.Dirs.Migrations
+
+	cfg, cfgErr := config.Load("")
+	if cfgErr != nil && !os.IsNotExist(cfgErr) {
+		return nil, cfgErr
+	}
+
+	if all {
+		if connection != "" || database != "" {
+			return nil, fmt.Errorf("--all cannot be combined with --connection or --database")
+		}
+		if cfgErr != nil {
+			return nil, fmt.Errorf("--all requires a config file: %w", cfgErr)
+		}
+		var targets []target
+		for _, name := range cfg.ConnectionNames() {
+			db, err := cfg.Connection(name)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, target{name: name, path: project.Path(db.MigrationsPath(base, name)), database: db.URL()})
+		}
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("no database connections configured")
+		}
+		return targets, nil
+	}
+
+	t := target{name: connection, path: path, database: database}
+	if connection != "" {
+		if cfgErr != nil {
+			return nil, fmt.Errorf("--connection requires a config file: %w", cfgErr)
+		}
+		db, err := cfg.Connection(connection)
+		if err != nil {
+			return nil, err
+		}
+		if !pathSet {
+			t.path = project.Path(db.MigrationsPath(base, connection))
+		}
+		if t.database == "" {
+			t.database = db.URL()
+		}
+		return []target{t}, nil
+	}
+
+	if !pathSet {
+		t.path = project.Path(base)
+		if cfgErr == nil {
+			t.path = project.Path(cfg.Database.MigrationsPath(base, config.DefaultConnection))
+		}
+	}
+	if t.database == "" && cfgErr == nil {
+		db, err := cfg.Connection(config.DefaultConnection)
+		if err != nil {
+			return nil, err
+		}
+		t.database = db.URL()
+	}
+	if t.database == "" {
+		t.database = config.Getenv("DATABASE_URL")
+	}
+	return []target{t}, nil
+}
+
+// runTargets runs the given migrate action against every resolved target,
+// labelling the output when more than one connection is involved.
+func runTargets(c *cobra.Command, args ...string) error {
+	targets, err := resolveTargets(c)
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "up", "down", "force":
+		announceEnvironment()
+	}
+	for _, t := range targets {
+		if len(targets) > 1 || t.name != "" {
+			ui.Info(fmt.Sprintf("Connection: %s", t.name))
+		}
+		printDSN(c, t.database)
+		if err := Run(t.path, t.database, args...); err != nil {
+			if t.name != "" {
+				return fmt.Errorf("connection %s: %w", t.name, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// announceEnvironment prints the resolved environment so destructive
+// commands never run against an unexpected target silently.
+func announceEnvironment() {
+	env := "(none)"
+	if cfg, err := config.Load(""); err == nil && cfg.Environment() != "" {
+		env = cfg.Environment()
+	}
+	ui.Info("Environment: " + env)
+}
+
+// printDSN prints the resolved DSN with the password masked when the
+// --print-dsn flag is set.
+func printDSN(c *cobra.Command, database string) {
+	if show, _ := c.Flags().GetBool("print-dsn"); show {
+		ui.Info("DSN: " + config.MaskDSN(database))
+	}
+}
+
 func makeUpCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "up [steps]",
 		Short: "Run up migrations (all or given steps)",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(c *cobra.Command, args []string) error {
-			path, _ := c.Flags().GetString("path")
-			database, _ := c.Flags().GetString("database")
 			if len(args) == 0 {
-				return Run(path, database, "up")
+				return runTargets(c, "up")
 			}
-			return Run(path, database, "up", args[0])
+			return runTargets(c, "up", args[0])
 		},
 	}
-	cmd.Flags().String("path", "database/migrations", "migrations directory")
-	// default database from config.json if available, else env DATABASE_URL
-	dbDefault := os.Getenv("DATABASE_URL")
-	if cfg, err := config.Load(""); err == nil {
-		if d := cfg.DatabaseURL(); d != "" {
-			dbDefault = d
-		}
-	}
-	cmd.Flags().String("database", dbDefault, "database URL")
+	addTargetFlags(cmd, true)
 	return cmd
 }
 
@@ -148,23 +297,14 @@ func makeRollbackCmd() *cobra.Command {
 		Short: "Rollback (down) migrations (defaults to 1 step)",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(c *cobra.Command, args []string) error {
-			path, _ := c.Flags().GetString("path")
-			database, _ := c.Flags().GetString("database")
 			steps := "1"
 			if len(args) == 1 {
 				steps = args[0]
 			}
-			return Run(path, database, "down", steps)
+			return runTargets(c, "down", steps)
 		},
 	}
-	cmd.Flags().String("path", "database/migrations", "migrations directory")
-	dbDefault := os.Getenv("DATABASE_URL")
-	if cfg, err := config.Load(""); err == nil {
-		if d := cfg.DatabaseURL(); d != "" {
-			dbDefault = d
-		}
-	}
-	cmd.Flags().String("database", dbDefault, "database URL")
+	addTargetFlags(cmd, true)
 	return cmd
 }
 
@@ -174,19 +314,10 @@ func makeForceCmd() *cobra.Command {
 		Short: "Set migration version without running migrations",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(c *cobra.Command, args []string) error {
-			path, _ := c.Flags().GetString("path")
-			database, _ := c.Flags().GetString("database")
-			return Run(path, database, "force", args[0])
+			return runTargets(c, "force", args[0])
 		},
 	}
-	cmd.Flags().String("path", "database/migrations", "migrations directory")
-	dbDefault := os.Getenv("DATABASE_URL")
-	if cfg, err := config.Load(""); err == nil {
-		if d := cfg.DatabaseURL(); d != "" {
-			dbDefault = d
-		}
-	}
-	cmd.Flags().String("database", dbDefault, "database URL")
+	addTargetFlags(cmd, false)
 	return cmd
 }
 
@@ -195,19 +326,10 @@ func makeVersionCmd() *cobra.Command {
 		Use:   "version",
 		Short: "Print the current migration version and state",
 		RunE: func(c *cobra.Command, args []string) error {
-			path, _ := c.Flags().GetString("path")
-			database, _ := c.Flags().GetString("database")
-			return Run(path, database, "version")
+			return runTargets(c, "version")
 		},
 	}
-	cmd.Flags().String("path", "database/migrations", "migrations directory")
-	dbDefault := os.Getenv("DATABASE_URL")
-	if cfg, err := config.Load(""); err == nil {
-		if d := cfg.DatabaseURL(); d != "" {
-			dbDefault = d
-		}
-	}
-	cmd.Flags().String("database", dbDefault, "database URL")
+	addTargetFlags(cmd, true)
 	return cmd
 }
 
@@ -216,6 +338,7 @@ func MigrateCmd() *cobra.Command {
 		Use:   "migrate",
 		Short: "Database migration commands",
 	}
-	migrateCmd.AddCommand(makeUpCmd(), makeRollbackCmd(), makeForceCmd(), makeVersionCmd())
+	migrateCmd.PersistentFlags().Bool("print-dsn", false, "print the resolved database DSN (password masked)")
+	migrateCmd.AddCommand(makeUpCmd(), makeRollbackCmd(), makeForceCmd(), makeVersionCmd(), makeTestCmd())
 	return migrateCmd
 }
@@ -0,0 +1,315 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	mg "github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/spf13/cobra"
+
+	"github.com/go-kyugo/kygo/internal/config"
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// RoundTripFailure describes a migration whose down step does not restore
+// the schema that existed before its up step (or whose up step cannot be
+// re-applied cleanly).
+type RoundTripFailure struct {
+	Version    uint
+	Identifier string
+	Reason     string
+	Missing    []string // schema lines present before but not after
+	Extra      []string // schema lines present after but not before
+}
+
+// RoundTrip applies every migration in migrationsPath one at a time, running
+// it up, down and up again while comparing schema snapshots between steps.
+// When database is empty a throwaway SQLite database is used.
+func RoundTrip(migrationsPath, database string) ([]RoundTripFailure, error) {
+	if database == "" {
+		tmp, err := os.MkdirTemp("", "kygo-migrate-test-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		database = "sqlite://" + filepath.Join(tmp, "roundtrip.db")
+	}
+
+	abs, err := filepath.Abs(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+	pathArg := "file://" + abs
+
+	src, err := source.Open(pathArg)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	db, err := openDB(database)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	m, err := mg.New(pathArg, database)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = m.Close()
+	}()
+
+	if v, _, err := m.Version(); err == nil {
+		return nil, fmt.Errorf("database is already at version %d; migrate test needs an empty database", v)
+	} else if err != mg.ErrNilVersion {
+		return nil, err
+	}
+
+	var failures []RoundTripFailure
+	version, err := src.First()
+	for err == nil {
+		identifier := identifierFor(src, version)
+		label := fmt.Sprintf("%d_%s", version, identifier)
+
+		before, serr := snapshot(db, database)
+		if serr != nil {
+			return failures, serr
+		}
+		if serr := m.Steps(1); serr != nil {
+			failures = append(failures, RoundTripFailure{Version: version, Identifier: identifier, Reason: "up failed: " + serr.Error()})
+			ui.Errorf("FAIL %s: up failed: %v", label, serr)
+			return failures, nil
+		}
+		after, serr := snapshot(db, database)
+		if serr != nil {
+			return failures, serr
+		}
+		if serr := m.Steps(-1); serr != nil {
+			failures = append(failures, RoundTripFailure{Version: version, Identifier: identifier, Reason: "down failed: " + serr.Error()})
+			ui.Errorf("FAIL %s: down failed: %v", label, serr)
+			return failures, nil
+		}
+		reverted, serr := snapshot(db, database)
+		if serr != nil {
+			return failures, serr
+		}
+		ok := true
+		if missing, extra := diffSnapshots(before, reverted); len(missing)+len(extra) > 0 {
+			ok = false
+			failures = append(failures, RoundTripFailure{Version: version, Identifier: identifier, Reason: "down does not restore the previous schema", Missing: missing, Extra: extra})
+			ui.Errorf("FAIL %s: down does not restore the previous schema", label)
+			printSchemaDiff(missing, extra)
+		}
+		if serr := m.Steps(1); serr != nil {
+			failures = append(failures, RoundTripFailure{Version: version, Identifier: identifier, Reason: "re-running up failed: " + serr.Error()})
+			ui.Errorf("FAIL %s: re-running up failed: %v", label, serr)
+			return failures, nil
+		}
+		again, serr := snapshot(db, database)
+		if serr != nil {
+			return failures, serr
+		}
+		if missing, extra := diffSnapshots(after, again); len(missing)+len(extra) > 0 {
+			ok = false
+			failures = append(failures, RoundTripFailure{Version: version, Identifier: identifier, Reason: "re-running up produced a different schema", Missing: missing, Extra: extra})
+			ui.Errorf("FAIL %s: re-running up produced a different schema", label)
+			printSchemaDiff(missing, extra)
+		}
+		if ok {
+			ui.Success("ok   " + label)
+		}
+
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return failures, err
+	}
+	return failures, nil
+}
+
+func identifierFor(src source.Driver, version uint) string {
+	r, identifier, err := src.ReadUp(version)
+	if err != nil {
+		return ""
+	}
+	_ = r.Close()
+	return identifier
+}
+
+func printSchemaDiff(missing, extra []string) {
+	for _, l := range missing {
+		ui.Println("  - " + l)
+	}
+	for _, l := range extra {
+		ui.Println("  + " + l)
+	}
+}
+
+// openDB opens a database/sql handle for a golang-migrate database URL so the
+// schema can be inspected between migration steps.
+func openDB(database string) (*sql.DB, error) {
+	scheme, _, ok := strings.Cut(database, "://")
+	if !ok {
+		return nil, fmt.Errorf("invalid database URL: %s", database)
+	}
+	switch scheme {
+	case "sqlite", "sqlite3":
+		u, err := url.Parse(database)
+		if err != nil {
+			return nil, err
+		}
+		return sql.Open("sqlite", strings.TrimPrefix(mg.FilterCustomQuery(u).String(), scheme+"://"))
+	case "postgres", "postgresql":
+		u, err := url.Parse(database)
+		if err != nil {
+			return nil, err
+		}
+		return sql.Open("postgres", mg.FilterCustomQuery(u).String())
+	case "mysql":
+		cfg, err := mysql.ParseDSN(strings.TrimPrefix(database, "mysql://"))
+		if err != nil {
+			return nil, err
+		}
+		// credentials in migrate URLs are query-escaped
+		if cfg.User, err = url.QueryUnescape(cfg.User); err != nil {
+			return nil, err
+		}
+		if cfg.Passwd, err = url.QueryUnescape(cfg.Passwd); err != nil {
+			return nil, err
+		}
+		return sql.Open("mysql", cfg.FormatDSN())
+	default:
+		return nil, fmt.Errorf("migrate test does not support %s databases", scheme)
+	}
+}
+
+// snapshot returns a sorted, line-based description of the current schema,
+// excluding the migrations bookkeeping table.
+func snapshot(db *sql.DB, database string) ([]string, error) {
+	scheme, _, _ := strings.Cut(database, "://")
+	var queries []string
+	switch scheme {
+	case "sqlite", "sqlite3":
+		queries = []string{
+			`SELECT type, name, tbl_name, COALESCE(sql, '') FROM sqlite_master
+			 WHERE name NOT LIKE 'sqlite_%' AND tbl_name <> 'schema_migrations'`,
+		}
+	case "postgres", "postgresql":
+		queries = []string{
+			`SELECT 'column', table_name, column_name, data_type || ' ' || is_nullable || ' ' || COALESCE(column_default, '')
+			 FROM information_schema.columns
+			 WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'`,
+			`SELECT 'index', tablename, indexname, indexdef FROM pg_indexes
+			 WHERE schemaname = current_schema() AND tablename <> 'schema_migrations'`,
+		}
+	case "mysql":
+		queries = []string{
+			`SELECT 'column', table_name, column_name, CONCAT(column_type, ' ', is_nullable, ' ', COALESCE(column_default, ''))
+			 FROM information_schema.columns
+			 WHERE table_schema = DATABASE() AND table_name <> 'schema_migrations'`,
+			`SELECT 'index', table_name, index_name, CONCAT(column_name, ' ', seq_in_index, ' ', non_unique)
+			 FROM information_schema.statistics
+			 WHERE table_schema = DATABASE() AND table_name <> 'schema_migrations'`,
+		}
+	default:
+		return nil, fmt.Errorf("migrate test does not support %s databases", scheme)
+	}
+
+	var lines []string
+	for _, q := range queries {
+		rows, err := db.Query(q)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var a, b, c, d string
+			if err := rows.Scan(&a, &b, &c, &d); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			lines = append(lines, strings.Join([]string{a, b, c, strings.Join(strings.Fields(d), " ")}, " | "))
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rows.Close()
+	}
+	sort.Strings(lines)
+	return lines, nil
+}
+
+// diffSnapshots returns the lines only present in want (missing) and the
+// lines only present in got (extra).
+func diffSnapshots(want, got []string) (missing, extra []string) {
+	seen := make(map[string]int, len(got))
+	for _, l := range got {
+		seen[l]++
+	}
+	for _, l := range want {
+		if seen[l] > 0 {
+			seen[l]--
+			continue
+		}
+		missing = append(missing, l)
+	}
+	for _, l := range got {
+		if seen[l] > 0 {
+			seen[l]--
+			extra = append(extra, l)
+		}
+	}
+	return missing, extra
+}
+
+func makeTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Verify every migration round-trips (up, down, up) cleanly",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			// only the migrations path comes from the connection config; the
+			// database is a throwaway one unless --database is given
+			database, _ := c.Flags().GetString("database")
+			targets, err := resolveTargets(c)
+			if err != nil {
+				return err
+			}
+			failed := 0
+			for _, t := range targets {
+				if t.name != "" {
+					ui.Info(fmt.Sprintf("Connection: %s", t.name))
+				}
+				if database == "" {
+					ui.Info(fmt.Sprintf("Testing migrations from %s against a throwaway SQLite database", t.path))
+				} else {
+					printDSN(c, database)
+					ui.Info(fmt.Sprintf("Testing migrations from %s -> %s", t.path, config.MaskDSN(database)))
+				}
+				failures, err := RoundTrip(t.path, database)
+				if err != nil {
+					return err
+				}
+				failed += len(failures)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d migration check(s) failed", failed)
+			}
+			ui.Success("All migrations round-trip cleanly")
+			return nil
+		},
+	}
+	addTargetFlags(cmd, true)
+	cmd.Flags().Lookup("database").Usage = "database URL (default: throwaway SQLite database)"
+	return cmd
+}
@@ -0,0 +1,143 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// FileName is the project manifest read by every command.
+const FileName = "kygo.json"
+
+// Migration version styles for generated migrations.
+const (
+	VersionTimestamp = "timestamp" // 20060102150405_name.up.sql
+	VersionSequence  = "sequence"  // 000001_name.up.sql
+)
+
+// Project describes the layout and conventions of a kyugo project. Every
+// field has a default, so kygo.json only needs the values a team changes.
+type Project struct {
+	Module     string     `json:"module,omitempty"`
+	Dialect    string     `json:"dialect,omitempty"`
+	Dirs       Dirs       `json:"dirs"`
+	Generators Generators `json:"generators"`
+	Build      Build      `json:"build"`
+}
+
+// Dirs are the directories, relative to the project root, where commands
+// read and write each kind of file.
+type Dirs struct {
+	Controller string `json:"controller"`
+	Model      string `json:"model"`
+	Repository string `json:"repository"`
+	Service    string `json:"service"`
+	Middleware string `json:"middleware"`
+	Migrations string `json:"migrations"`
+	Seed       string `json:"seed"`
+	DTO        string `json:"dto"`
+	Validation string `json:"validation"`
+	Config     string `json:"config"`
+	Route      string `json:"route"`
+	Docs       string `json:"docs"`
+	Lang       string `json:"lang"`
+}
+
+// Generators holds options for `kygo create`.
+type Generators struct {
+	// RegisterControllers adds new controllers to the route file.
+	RegisterControllers bool `json:"register_controllers"`
+	// MigrationVersion is VersionTimestamp or VersionSequence.
+	MigrationVersion string `json:"migration_version"`
+}
+
+// Build holds the defaults of `kygo build`.
+type Build struct {
+	// Name of the binary; defaults to the last element of the module path.
+	Name    string `json:"name,omitempty"`
+	Package string `json:"package"` // main package to build
+	Output  string `json:"output"`  // directory for artefacts
+	// Targets are GOOS/GOARCH pairs; empty builds for the host only.
+	Targets []string `json:"targets,omitempty"`
+	// Include lists files and directories packed into archives besides
+	// the example config and resources/.
+	Include []string `json:"include,omitempty"`
+	// VersionPackage is the package whose version, commit and buildTime
+	// variables are set through -ldflags.
+	VersionPackage string `json:"version_package"`
+	Ldflags        string `json:"ldflags,omitempty"` // extra linker flags, e.g. "-s -w"
+	Archive        bool   `json:"archive"`           // pack each target into a .tar.gz
+}
+
+// Default returns the layout generated by kygo init.
+func Default() *Project {
+	return &Project{
+		Dirs: Dirs{
+			Controller: "http/controller",
+			Model:      "database/model",
+			Repository: "database/repository",
+			Service:    "service",
+			Middleware: "http/middleware",
+			Migrations: "database/migrations",
+			Seed:       "database/seed",
+			DTO:        "dto",
+			Validation: "http/validation",
+			Config:     "config",
+			Route:      "http/route",
+			Docs:       "resources/docs",
+			Lang:       "resources/lang",
+		},
+		Generators: Generators{
+			RegisterControllers: true,
+			MigrationVersion:    VersionTimestamp,
+		},
+		Build: Build{
+			Package:        ".",
+			Output:         "dist",
+			VersionPackage: "main",
+			Archive:        true,
+		},
+	}
+}
+
+// Load reads kygo.json from root over the defaults. A missing file is not an
+// error. When the manifest doesn't name the module, it is read from go.mod.
+func Load(root string) (*Project, error) {
+	p := Default()
+	b, err := os.ReadFile(filepath.Join(root, FileName))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(b, p); err != nil {
+			return nil, fmt.Errorf("%s: %w", FileName, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	switch p.Generators.MigrationVersion {
+	case VersionTimestamp, VersionSequence:
+	default:
+		return nil, fmt.Errorf("%s: generators.migration_version must be %q or %q", FileName, VersionTimestamp, VersionSequence)
+	}
+	if p.Module == "" {
+		if b, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			p.Module = modfile.ModulePath(b)
+		}
+	}
+	if p.Build.Name == "" && p.Module != "" {
+		p.Build.Name = path.Base(p.Module)
+	}
+	return p, nil
+}
+
+// Marshal encodes p as written to kygo.json.
+func (p *Project) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
@@ -0,0 +1,73 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// root caches the directory found by Root.
+var root string
+
+// SetDir makes dir the working directory, as given with -C/--project-dir,
+// so paths on the command line are relative to it and the project is
+// looked up from there.
+func SetDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("--project-dir: %w", err)
+	}
+	root = ""
+	return nil
+}
+
+// Root returns the project root: the closest directory, starting at the
+// working directory and walking up, that holds kygo.json or go.mod. When
+// there is none the working directory is the root.
+func Root() string {
+	if root != "" {
+		return root
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	root = wd
+	for dir := wd; ; {
+		if isRoot(dir) {
+			root = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return root
+}
+
+func isRoot(dir string) bool {
+	for _, name := range []string{FileName, "go.mod"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Path resolves a path relative to the project root, such as a directory
+// from kygo.json. Absolute paths are returned unchanged.
+func Path(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(Root(), filepath.FromSlash(p))
+}
+
+// Current loads kygo.json from the project root.
+func Current() (*Project, error) {
+	return Load(Root())
+}
@@ -0,0 +1,403 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Route is an HTTP route found in the project's source.
+type Route struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware"`
+	Controller string   `json:"controller,omitempty"`
+	Source     string   `json:"source"` // file:line of the registration
+}
+
+// methods maps kyugo.Router methods to the HTTP method they register.
+var methods = map[string]string{
+	"Get":     "GET",
+	"Post":    "POST",
+	"Put":     "PUT",
+	"Patch":   "PATCH",
+	"Delete":  "DELETE",
+	"Head":    "HEAD",
+	"Options": "OPTIONS",
+	"Any":     "ANY",
+}
+
+// analyzer walks route registrations without type-checking the project, so
+// it works before dependencies are downloaded. It understands the calls the
+// templates use: router.<Method>(path, handler, middleware...),
+// router.Use(middleware...), router.Group(prefix, func(router *kyugo.Router))
+// and router.Controller(pkg.NewController()), which is followed into the
+// controller's RegisterRoutes method.
+type analyzer struct {
+	root     string
+	module   string
+	fset     *token.FileSet
+	packages map[string][]*ast.File // parsed packages by directory
+	Analysis
+}
+
+// Analysis is what Analyze found.
+type Analysis struct {
+	Routes   []Route
+	Warnings []string
+	// Missing lists controllers the route file registers whose package
+	// or constructor doesn't exist.
+	Missing []string
+}
+
+// scope is the router a block of statements registers routes on.
+type scope struct {
+	router     string   // name of the *kyugo.Router variable
+	prefix     string   // path prefix of enclosing groups
+	middleware []string // middleware applied by Use and enclosing groups
+	controller string   // controller whose RegisterRoutes is analysed
+	receiver   string   // receiver variable of that method
+	recvType   string   // and its type name
+}
+
+func newAnalyzer(root, module string) *analyzer {
+	return &analyzer{root: root, module: module, fset: token.NewFileSet(), packages: map[string][]*ast.File{}}
+}
+
+// parseDir parses the non-test Go files of dir, once.
+func (a *analyzer) parseDir(dir string) ([]*ast.File, error) {
+	if files, ok := a.packages[dir]; ok {
+		return files, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(a.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	a.packages[dir] = files
+	return files, nil
+}
+
+// routeFile analyses every function of the route package that takes a
+// *kyugo.Router, such as Register.
+func (a *analyzer) routeFile(dir string) error {
+	files, err := a.parseDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no Go files in %s", dir)
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil {
+				continue
+			}
+			if router := routerParam(fn.Type); router != "" {
+				a.block(f, fn.Body.List, scope{router: router})
+			}
+		}
+	}
+	return nil
+}
+
+// routerParam returns the name of the first *kyugo.Router parameter.
+func routerParam(fn *ast.FuncType) string {
+	for _, field := range fn.Params.List {
+		star, ok := field.Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+		if sel, ok := star.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "Router" && len(field.Names) > 0 {
+			return field.Names[0].Name
+		}
+	}
+	return ""
+}
+
+func (a *analyzer) block(f *ast.File, stmts []ast.Stmt, s scope) {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.BlockStmt:
+			a.block(f, stmt.List, s)
+		case *ast.ExprStmt:
+			if call, ok := stmt.X.(*ast.CallExpr); ok {
+				s = a.call(f, call, s)
+			}
+		}
+	}
+}
+
+// call records what a router call registers and returns the scope for the
+// statements after it.
+func (a *analyzer) call(f *ast.File, call *ast.CallExpr, s scope) scope {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return s
+	}
+	if x, ok := sel.X.(*ast.Ident); !ok || x.Name != s.router {
+		return s
+	}
+	name := sel.Sel.Name
+	switch {
+	case methods[name] != "":
+		if len(call.Args) < 2 {
+			return s
+		}
+		r := Route{
+			Method:     methods[name],
+			Path:       joinPath(s.prefix, stringValue(call.Args[0])),
+			Handler:    a.handler(call.Args[1], s),
+			Middleware: append([]string{}, s.middleware...),
+			Controller: s.controller,
+			Source:     a.position(call),
+		}
+		for _, mw := range call.Args[2:] {
+			r.Middleware = append(r.Middleware, types.ExprString(mw))
+		}
+		a.Routes = append(a.Routes, r)
+	case name == "Use":
+		s.middleware = append(append([]string{}, s.middleware...), exprStrings(call.Args)...)
+	case name == "Group":
+		if len(call.Args) < 2 {
+			return s
+		}
+		fn, ok := call.Args[len(call.Args)-1].(*ast.FuncLit)
+		if !ok {
+			a.warn(call, "group body is not a function literal; its routes are not listed")
+			return s
+		}
+		inner := s
+		inner.prefix = joinPath(s.prefix, stringValue(call.Args[0]))
+		inner.middleware = append(append([]string{}, s.middleware...), exprStrings(call.Args[1:len(call.Args)-1])...)
+		if inner.router = routerParam(fn.Type); inner.router == "" {
+			inner.router = s.router
+		}
+		a.block(f, fn.Body.List, inner)
+	case name == "Controller":
+		for _, arg := range call.Args {
+			a.controller(f, arg, s)
+		}
+	}
+	return s
+}
+
+// controller follows router.Controller(arg) into the RegisterRoutes method
+// of the controller's type.
+func (a *analyzer) controller(f *ast.File, arg ast.Expr, s scope) {
+	alias, typeName, constructor := controllerRef(arg)
+	if alias == "" {
+		a.warn(arg, "cannot tell which controller "+types.ExprString(arg)+" is; its routes are not listed")
+		return
+	}
+	importPath := importOf(f, alias)
+	if importPath == "" || (importPath != a.module && !strings.HasPrefix(importPath, a.module+"/")) {
+		a.warn(arg, "controller package "+alias+" is outside the module; its routes are not listed")
+		return
+	}
+	dir := filepath.Join(a.root, filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(importPath, a.module), "/")))
+	files, err := a.parseDir(dir)
+	if errors.Is(err, fs.ErrNotExist) || err == nil && len(files) == 0 {
+		a.missing(arg, "package "+importPath+" does not exist")
+		return
+	}
+	if err != nil {
+		a.warn(arg, err.Error())
+		return
+	}
+	if typeName == "" {
+		if typeName = resultType(files, constructor); typeName == "" {
+			a.missing(arg, alias+"."+constructor+" is not defined")
+			return
+		}
+	}
+	for _, cf := range files {
+		for _, decl := range cf.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Name != "RegisterRoutes" || fn.Recv == nil || fn.Body == nil {
+				continue
+			}
+			recv, recvType := receiver(fn)
+			if typeName != "" && recvType != typeName {
+				continue
+			}
+			router := routerParam(fn.Type)
+			if router == "" {
+				continue
+			}
+			a.block(cf, fn.Body.List, scope{
+				router:     router,
+				prefix:     s.prefix,
+				middleware: s.middleware,
+				controller: cf.Name.Name + "." + recvType,
+				receiver:   recv,
+				recvType:   recvType,
+			})
+			return
+		}
+	}
+	a.warn(arg, "no RegisterRoutes method found in "+importPath)
+}
+
+// controllerRef returns the package alias and, when it is written out, the
+// type of a controller expression: pkg.NewController(), &pkg.Controller{}
+// or pkg.Controller{}. For constructor calls the function name is returned
+// instead of the type.
+func controllerRef(e ast.Expr) (alias, typeName, constructor string) {
+	switch e := e.(type) {
+	case *ast.UnaryExpr:
+		return controllerRef(e.X)
+	case *ast.CompositeLit:
+		if sel, ok := e.Type.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				return x.Name, sel.Sel.Name, ""
+			}
+		}
+	case *ast.CallExpr:
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				return x.Name, "", sel.Sel.Name
+			}
+		}
+	}
+	return "", "", ""
+}
+
+// resultType returns the type name returned by the function called name.
+func resultType(files []*ast.File, name string) string {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Name.Name != name || fn.Type.Results == nil || len(fn.Type.Results.List) == 0 {
+				continue
+			}
+			return typeIdent(fn.Type.Results.List[0].Type)
+		}
+	}
+	return ""
+}
+
+// receiver returns the receiver variable and type name of a method.
+func receiver(fn *ast.FuncDecl) (string, string) {
+	field := fn.Recv.List[0]
+	name := ""
+	if len(field.Names) > 0 {
+		name = field.Names[0].Name
+	}
+	return name, typeIdent(field.Type)
+}
+
+func typeIdent(e ast.Expr) string {
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	if id, ok := e.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// importOf returns the import path f refers to as alias.
+func importOf(f *ast.File, alias string) string {
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == alias {
+			return p
+		}
+	}
+	return ""
+}
+
+// handler describes a route handler: Type.Method for methods of the
+// controller being analysed, "func literal" for inline handlers and the
+// source expression otherwise.
+func (a *analyzer) handler(e ast.Expr, s scope) string {
+	switch e := e.(type) {
+	case *ast.FuncLit:
+		return "func literal"
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && s.receiver != "" && x.Name == s.receiver {
+			return s.recvType + "." + e.Sel.Name
+		}
+	}
+	return types.ExprString(e)
+}
+
+func (a *analyzer) position(n ast.Node) string {
+	pos := a.fset.Position(n.Pos())
+	rel, err := filepath.Rel(a.root, pos.Filename)
+	if err != nil {
+		rel = pos.Filename
+	}
+	return fmt.Sprintf("%s:%d", filepath.ToSlash(rel), pos.Line)
+}
+
+func (a *analyzer) warn(n ast.Node, msg string) {
+	a.Warnings = append(a.Warnings, a.position(n)+": "+msg)
+}
+
+// missing records a controller that doesn't exist; it is also a warning
+// because its routes can't be listed.
+func (a *analyzer) missing(n ast.Node, msg string) {
+	a.Missing = append(a.Missing, a.position(n)+": "+msg)
+	a.warn(n, msg)
+}
+
+// stringValue returns the value of a string literal, or the source of any
+// other expression.
+func stringValue(e ast.Expr) string {
+	if lit, ok := e.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		if s, err := strconv.Unquote(lit.Value); err == nil {
+			return s
+		}
+	}
+	return "{" + types.ExprString(e) + "}"
+}
+
+func exprStrings(exprs []ast.Expr) []string {
+	out := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		out = append(out, types.ExprString(e))
+	}
+	return out
+}
+
+// joinPath appends p to a group prefix with exactly one slash between them.
+func joinPath(prefix, p string) string {
+	if prefix == "" {
+		return p
+	}
+	if p == "" || p == "/" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(p, "/")
+}
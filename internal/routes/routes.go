@@ -0,0 +1,152 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/ui"
+)
+
+// Output formats of `kygo routes`.
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// RoutesCmd returns the `routes` command.
+func RoutesCmd() *cobra.Command {
+	var method, pathFilter, format string
+	cmd := &cobra.Command{
+		Use:   "routes",
+		Short: "List the HTTP routes registered by the project",
+		Long: "Read the route file and the RegisterRoutes method of every controller it registers\n" +
+			"and list each route with its handler, middleware and controller. The source is\n" +
+			"analysed statically, so the project doesn't need to build.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case FormatTable, FormatJSON, FormatMarkdown:
+			default:
+				return fmt.Errorf("unsupported --format %q (use table, json or markdown)", format)
+			}
+			analysis, err := Analyze()
+			if err != nil {
+				return err
+			}
+			routes := Filter(analysis.Routes, method, pathFilter)
+			switch format {
+			case FormatJSON:
+				b, err := json.MarshalIndent(routes, "", "  ")
+				if err != nil {
+					return err
+				}
+				ui.Println(string(b))
+				// keep the output parseable; warnings are for people
+				return nil
+			case FormatMarkdown:
+				ui.Println(markdown(routes))
+			default:
+				if len(routes) == 0 {
+					ui.Info("No routes found")
+				} else {
+					ui.Println(table(routes))
+				}
+			}
+			for _, w := range analysis.Warnings {
+				ui.Warning(w)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&method, "method", "", "only list routes for these HTTP methods (comma-separated)")
+	cmd.Flags().StringVar(&pathFilter, "path", "", "only list routes whose path contains this text")
+	cmd.Flags().StringVar(&format, "format", FormatTable, "output format: table, json or markdown")
+	return cmd
+}
+
+// Analyze lists the routes of the project at the project root.
+func Analyze() (*Analysis, error) {
+	proj, err := project.Current()
+	if err != nil {
+		return nil, err
+	}
+	a := newAnalyzer(project.Root(), proj.Module)
+	if err := a.routeFile(project.Path(proj.Dirs.Route)); err != nil {
+		return nil, err
+	}
+	return &a.Analysis, nil
+}
+
+// Filter keeps the routes matching any of the comma-separated methods
+// (routes registered with Any match every method) and whose path contains
+// pathFilter. Empty filters match everything.
+func Filter(routes []Route, methods, pathFilter string) []Route {
+	want := map[string]bool{}
+	for _, m := range strings.Split(methods, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			want[strings.ToUpper(m)] = true
+		}
+	}
+	out := []Route{}
+	for _, r := range routes {
+		if len(want) > 0 && !want[r.Method] && r.Method != "ANY" {
+			continue
+		}
+		if !strings.Contains(r.Path, pathFilter) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+var columns = []string{"METHOD", "PATH", "HANDLER", "MIDDLEWARE", "CONTROLLER"}
+
+func cells(r Route) []string {
+	return []string{r.Method, r.Path, r.Handler, strings.Join(r.Middleware, ", "), r.Controller}
+}
+
+func table(routes []Route) string {
+	widths := make([]int, len(columns))
+	rows := [][]string{columns}
+	for _, r := range routes {
+		rows = append(rows, cells(r))
+	}
+	for _, row := range rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], len(c))
+		}
+	}
+	lines := make([]string, len(rows))
+	for n, row := range rows {
+		var b strings.Builder
+		for i, c := range row {
+			fmt.Fprintf(&b, "%-*s  ", widths[i], c)
+		}
+		lines[n] = strings.TrimRight(b.String(), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func markdown(routes []Route) string {
+	var b strings.Builder
+	b.WriteString("| Method | Path | Handler | Middleware | Controller |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |")
+	for _, r := range routes {
+		row := cells(r)
+		for i, c := range row {
+			c = strings.ReplaceAll(c, "|", `\|`)
+			if i == 1 || i == 2 {
+				c = "`" + c + "`"
+			}
+			row[i] = c
+		}
+		b.WriteString("\n| " + strings.Join(row, " | ") + " |")
+	}
+	return b.String()
+}
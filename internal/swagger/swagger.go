@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/go-kyugo/kygo/internal/project"
 	"github.com/go-kyugo/kygo/internal/ui"
 )
 
@@ -17,27 +18,6 @@ func SwaggerCmd() *cobra.Command {
 		Short: "Swagger generation and tooling",
 	}
 
-	// helper to run swag via `go run`
-	runSwag := func(dir string, args []string) error {
-		if _, err := exec.LookPath("go"); err != nil {
-			ui.Errorf("Go tool not found in PATH: %w", err)
-			return err
-		}
-
-		c := exec.Command("go", args...)
-		c.Dir = dir
-		c.Stdout = os.Stdout
-		c.Stderr = os.Stderr
-		c.Stdin = os.Stdin
-
-		ui.Info("Running: go " + strings.Join(args, " "))
-		if err := c.Run(); err != nil {
-			ui.Errorf("Failed to run swag via go run: %w", err)
-			return err
-		}
-		return nil
-	}
-
 	// init subcommand: generate docs (alias behavior)
 	var dir string
 	var mainFile string
@@ -46,15 +26,16 @@ func SwaggerCmd() *cobra.Command {
 		Use:   "init",
 		Short: "Generate swagger docs from annotations (runs swag via go run)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			runArgs := []string{"run", "github.com/swaggo/swag/cmd/swag@latest", "init"}
-			if mainFile != "" {
-				runArgs = append(runArgs, "-g", mainFile)
+			if dir == "" {
+				dir = project.Root()
 			}
-			if outDir != "" {
-				runArgs = append(runArgs, "-o", outDir)
+			if outDir == "" {
+				var err error
+				if outDir, err = docsDir(dir); err != nil {
+					return err
+				}
 			}
-
-			if err := runSwag(dir, runArgs); err != nil {
+			if err := Generate(dir, mainFile, outDir); err != nil {
 				ui.Errorf("%v", err)
 				return err
 			}
@@ -64,9 +45,9 @@ func SwaggerCmd() *cobra.Command {
 		},
 	}
 
-	initCmd.Flags().StringVarP(&dir, "dir", "C", ".", "Project Directory (default: .)")
+	initCmd.Flags().StringVar(&dir, "dir", "", "Project directory (default: the project root)")
 	initCmd.Flags().StringVarP(&mainFile, "main", "g", "main.go", "Main file to analyze (default: main.go)")
-	initCmd.Flags().StringVarP(&outDir, "out", "o", "resources/docs", "Output directory for Swagger docs (default: resources/docs)")
+	initCmd.Flags().StringVarP(&outDir, "out", "o", "", "Output directory for Swagger docs (default: dirs.docs from kygo.json, resources/docs)")
 
 	// generate subcommand: alias to init (for semantics)
 	var dirG string
@@ -76,15 +57,16 @@ func SwaggerCmd() *cobra.Command {
 		Use:   "generate",
 		Short: "Generate swagger docs (alias to init)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			runArgs := []string{"run", "github.com/swaggo/swag/cmd/swag@latest", "init"}
-			if mainFileG != "" {
-				runArgs = append(runArgs, "-g", mainFileG)
+			if dirG == "" {
+				dirG = project.Root()
 			}
-			if outDirG != "" {
-				runArgs = append(runArgs, "-o", outDirG)
+			if outDirG == "" {
+				var err error
+				if outDirG, err = docsDir(dirG); err != nil {
+					return err
+				}
 			}
-
-			if err := runSwag(dirG, runArgs); err != nil {
+			if err := Generate(dirG, mainFileG, outDirG); err != nil {
 				ui.Errorf("%v", err)
 				return err
 			}
@@ -94,10 +76,48 @@ func SwaggerCmd() *cobra.Command {
 		},
 	}
 
-	generateCmd.Flags().StringVarP(&dirG, "dir", "C", ".", "Project Directory (default: .)")
+	generateCmd.Flags().StringVar(&dirG, "dir", "", "Project directory (default: the project root)")
 	generateCmd.Flags().StringVarP(&mainFileG, "main", "g", "main.go", "Main file to analyze (default: main.go)")
-	generateCmd.Flags().StringVarP(&outDirG, "out", "o", "resources/docs", "Output directory for Swagger docs (default: resources/docs)")
+	generateCmd.Flags().StringVarP(&outDirG, "out", "o", "", "Output directory for Swagger docs (default: dirs.docs from kygo.json, resources/docs)")
 
 	root.AddCommand(initCmd, generateCmd)
 	return root
 }
+
+// Generate runs swag (via `go run`) in dir to generate docs for the API
+// whose general annotations are in mainFile, writing them to outDir.
+func Generate(dir, mainFile, outDir string) error {
+	if _, err := exec.LookPath("go"); err != nil {
+		ui.Errorf("Go tool not found in PATH: %w", err)
+		return err
+	}
+	args := []string{"run", "github.com/swaggo/swag/cmd/swag@latest", "init"}
+	if mainFile != "" {
+		args = append(args, "-g", mainFile)
+	}
+	if outDir != "" {
+		args = append(args, "-o", outDir)
+	}
+
+	c := exec.Command("go", args...)
+	c.Dir = dir
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Stdin = os.Stdin
+
+	ui.Info("Running: go " + strings.Join(args, " "))
+	if err := c.Run(); err != nil {
+		ui.Errorf("Failed to run swag via go run: %w", err)
+		return err
+	}
+	return nil
+}
+
+// docsDir returns the docs directory configured for the project in dir.
+func docsDir(dir string) (string, error) {
+	proj, err := project.Load(dir)
+	if err != nil {
+		return "", err
+	}
+	return proj.Dirs.Docs, nil
+}
@@ -30,12 +30,32 @@ func Errorf(format string, a ...interface{}) {
 	c.Printf(format+"\n", a...)
 }
 
+// Warning prints a warning message in yellow.
+func Warning(msg string) {
+	c := color.New(color.FgYellow)
+	c.Printf("Warning: %s\n", msg)
+}
+
 // Info prints an informational message in cyan.
 func Info(msg string) {
 	c := color.New(color.FgCyan)
 	c.Printf("%s\n", msg)
 }
 
+// Check prints the outcome of a check as a coloured PASS, WARN or FAIL label
+// followed by msg. Any other status is printed as is.
+func Check(status, msg string) {
+	c := color.New(color.FgGreen)
+	switch status {
+	case "WARN":
+		c = color.New(color.FgYellow)
+	case "FAIL":
+		c = color.New(color.FgRed, color.Bold)
+	}
+	c.Printf("%-4s", status)
+	fmt.Printf("  %s\n", msg)
+}
+
 // Usage prints usage/help text in yellow.
 func Usage(msg string) {
 	c := color.New(color.FgYellow)
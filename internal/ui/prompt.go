@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"golang.org/x/term"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// Interactive reports whether stdin is a terminal, i.e. whether prompting
+// the user makes sense.
+func Interactive() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// readLine reads one trimmed line from stdin.
+func readLine() string {
+	line, _ := stdin.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// Prompt asks for a free-form value, returning def when the answer is empty.
+func Prompt(label, def string) string {
+	c := color.New(color.FgCyan)
+	if def != "" {
+		c.Printf("%s [%s]: ", label, def)
+	} else {
+		c.Printf("%s: ", label)
+	}
+	if v := readLine(); v != "" {
+		return v
+	}
+	return def
+}
+
+// PromptSecret asks for a value such as a password without echoing it,
+// returning def when the answer is empty. The default is never shown.
+func PromptSecret(label, def string) string {
+	color.New(color.FgCyan).Printf("%s: ", label)
+	var v string
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err == nil {
+			v = strings.TrimSpace(string(b))
+		}
+	} else {
+		v = readLine()
+	}
+	if v != "" {
+		return v
+	}
+	return def
+}
+
+// Select asks the user to pick one of options, either by number or by
+// name, and repeats the question until the answer is valid.
+func Select(label string, options []string, def string) string {
+	c := color.New(color.FgCyan)
+	for {
+		c.Printf("%s\n", label)
+		for i, o := range options {
+			fmt.Printf("  %d) %s\n", i+1, o)
+		}
+		v := Prompt("Choose", def)
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1]
+		}
+		for _, o := range options {
+			if strings.EqualFold(o, v) {
+				return o
+			}
+		}
+		Errorf("%q is not one of the options", v)
+	}
+}
+
+// Confirm asks a yes/no question.
+func Confirm(label string, def bool) bool {
+	c := color.New(color.FgCyan)
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		c.Printf("%s [%s]: ", label, hint)
+		switch strings.ToLower(readLine()) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
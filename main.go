@@ -1,13 +1,21 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
+	"github.com/go-kyugo/kygo/internal/build"
+	"github.com/go-kyugo/kygo/internal/config"
 	"github.com/go-kyugo/kygo/internal/create"
+	"github.com/go-kyugo/kygo/internal/dev"
+	"github.com/go-kyugo/kygo/internal/doctor"
 	initpkg "github.com/go-kyugo/kygo/internal/init"
+	"github.com/go-kyugo/kygo/internal/lang"
 	migrate "github.com/go-kyugo/kygo/internal/migrate"
+	"github.com/go-kyugo/kygo/internal/project"
+	"github.com/go-kyugo/kygo/internal/routes"
 	"github.com/go-kyugo/kygo/internal/swagger"
 	"github.com/go-kyugo/kygo/internal/ui"
 )
@@ -16,20 +24,43 @@ var rootCmd = &cobra.Command{
 	Use:     "kygo",
 	Short:   "Kygo CLI",
 	Version: "1.0.0",
+	// main prints the error once; failed checks and conflicts are outcomes,
+	// not usage mistakes
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		env, _ := cmd.Flags().GetString("env")
+		config.SetEnvironment(env)
+		dir, _ := cmd.Flags().GetString("project-dir")
+		return project.SetDir(dir)
+	},
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringP("project-dir", "C", "", "run as if kygo was started in this directory; the project root is found from there")
+	rootCmd.PersistentFlags().String("env", "", "environment whose config.<env>.json is merged over config.json (default: KYGO_ENV or app.environment)")
 	rootCmd.AddCommand(create.CreateCmd)
 	rootCmd.AddCommand(initpkg.MakeInitCmd())
-	kinds := []string{"controller", "model", "repository", "service", "middleware", "migration", "seed", "dto", "validation"}
+	rootCmd.AddCommand(initpkg.UpgradeCmd())
+	kinds := []string{"controller", "model", "repository", "service", "middleware", "migration", "seed", "dto", "validation", "config"}
 	for _, k := range kinds {
 		create.CreateCmd.AddCommand(create.CreateKindCmd(k))
 	}
+	create.CreateCmd.AddCommand(create.CreateDockerCmd())
 	rootCmd.AddCommand(migrate.MigrateCmd())
 	rootCmd.AddCommand(swagger.SwaggerCmd())
+	rootCmd.AddCommand(config.ConfigCmd())
+	rootCmd.AddCommand(routes.RoutesCmd())
+	rootCmd.AddCommand(dev.DevCmd())
+	rootCmd.AddCommand(build.BuildCmd())
+	rootCmd.AddCommand(doctor.DoctorCmd())
+	rootCmd.AddCommand(lang.LangCmd())
 }
 
 func main() {
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return fmt.Errorf("%w\nRun '%s --help' for usage.", err, cmd.CommandPath())
+	})
 	if err := rootCmd.Execute(); err != nil {
 		ui.Errorf("%v", err)
 		os.Exit(1)